@@ -0,0 +1,242 @@
+// Package audience resolves groups and contacts into the MSISDNs an SMS
+// message is sent to. This allows reporting the number of recipients, or
+// previewing them with a dry run, before anything is sent.
+package audience
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/contact"
+	"github.com/messagebird/go-rest-api/v9/group"
+	"github.com/messagebird/go-rest-api/v9/sms"
+)
+
+const (
+	// maximumRecipientsPerRequest is the maximum number of recipients a
+	// single SMS message can be sent to.
+	maximumRecipientsPerRequest = 50
+
+	// listPageSize is the number of groups or contacts requested per page.
+	listPageSize = 100
+)
+
+// Audience describes who a message is sent to. All fields are optional, but
+// at least one recipient must be resolved.
+type Audience struct {
+	// GroupNames are resolved to groups using group.List. All groups with a
+	// matching name are included.
+	GroupNames []string
+
+	GroupIDs   []string
+	ContactIDs []string
+	MSISDNs    []string
+}
+
+// Resolution is the result of resolving an Audience.
+type Resolution struct {
+	// GroupIDs are the IDs of all groups that were included, including those
+	// resolved from names.
+	GroupIDs []string
+
+	// MSISDNs is the sorted set of MSISDNs the message is sent to.
+	MSISDNs []string
+}
+
+// Count gets the final number of recipients.
+func (r *Resolution) Count() int {
+	return len(r.MSISDNs)
+}
+
+// SendOptions configures Send.
+type SendOptions struct {
+	// Params are passed to sms.Create. Its GroupIds are ignored: groups are
+	// resolved to recipients before sending.
+	Params *sms.Params
+
+	// DryRun resolves the recipients without sending the message.
+	DryRun bool
+}
+
+// Result is the result of Send.
+type Result struct {
+	Resolution
+
+	// Messages holds the created messages. Recipients are sent in batches of
+	// 50, so there is a message per batch. It is empty for dry runs.
+	Messages []*sms.Message
+}
+
+// Resolve looks up the groups and contacts of the audience and returns the
+// set of MSISDNs they resolve to.
+func Resolve(c messagebird.Client, a *Audience) (*Resolution, error) {
+	if a == nil {
+		return nil, errors.New("audience is required")
+	}
+
+	groupIDs := append([]string{}, a.GroupIDs...)
+	if len(a.GroupNames) > 0 {
+		ids, err := groupIDsByName(c, a.GroupNames)
+		if err != nil {
+			return nil, err
+		}
+		groupIDs = append(groupIDs, ids...)
+	}
+	groupIDs = unique(groupIDs)
+
+	msisdns := make(map[string]bool)
+	for _, msisdn := range a.MSISDNs {
+		msisdns[strings.TrimPrefix(msisdn, "+")] = true
+	}
+
+	for _, contactID := range unique(a.ContactIDs) {
+		ct, err := contact.Read(c, contactID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not read contact %s: %v", contactID, err)
+		}
+		msisdns[strconv.FormatInt(ct.MSISDN, 10)] = true
+	}
+
+	for _, groupID := range groupIDs {
+		if err := groupMSISDNs(c, groupID, msisdns); err != nil {
+			return nil, fmt.Errorf("could not list contacts of group %s: %v", groupID, err)
+		}
+	}
+
+	resolution := &Resolution{
+		GroupIDs: groupIDs,
+		MSISDNs:  make([]string, 0, len(msisdns)),
+	}
+	for msisdn := range msisdns {
+		resolution.MSISDNs = append(resolution.MSISDNs, msisdn)
+	}
+	sort.Strings(resolution.MSISDNs)
+
+	return resolution, nil
+}
+
+// Send resolves the audience and sends the message to the resulting MSISDNs,
+// unless options.DryRun is set. If sending a batch fails, the messages that
+// were created so far are returned with the error.
+func Send(c messagebird.Client, originator, body string, a *Audience, options *SendOptions) (*Result, error) {
+	if options == nil {
+		options = &SendOptions{}
+	}
+
+	resolution, err := Resolve(c, a)
+	if err != nil {
+		return nil, err
+	}
+	if resolution.Count() == 0 {
+		return nil, errors.New("audience has no recipients")
+	}
+
+	result := &Result{Resolution: *resolution}
+	if options.DryRun {
+		return result, nil
+	}
+
+	var params *sms.Params
+	if options.Params != nil {
+		p := *options.Params
+		p.GroupIds = nil
+		params = &p
+	}
+
+	for start := 0; start < len(resolution.MSISDNs); start += maximumRecipientsPerRequest {
+		end := start + maximumRecipientsPerRequest
+		if end > len(resolution.MSISDNs) {
+			end = len(resolution.MSISDNs)
+		}
+
+		message, err := sms.Create(c, originator, resolution.MSISDNs[start:end], body, params)
+		if err != nil {
+			return result, err
+		}
+		result.Messages = append(result.Messages, message)
+	}
+
+	return result, nil
+}
+
+// groupIDsByName pages through all groups and returns the IDs of those with
+// one of the given names. It returns an error if a name does not match any
+// group.
+func groupIDsByName(c messagebird.Client, names []string) ([]string, error) {
+	found := make(map[string]bool, len(names))
+	for _, name := range names {
+		found[name] = false
+	}
+
+	var ids []string
+	for offset := 0; ; {
+		groups, err := group.List(c, &messagebird.PaginationRequest{Limit: listPageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, g := range groups.Items {
+			if _, ok := found[g.Name]; ok {
+				found[g.Name] = true
+				ids = append(ids, g.ID)
+			}
+		}
+
+		offset += len(groups.Items)
+		if len(groups.Items) == 0 || offset >= groups.TotalCount {
+			break
+		}
+	}
+
+	var missing []string
+	for name, ok := range found {
+		if !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("no groups found named %s", strings.Join(missing, ", "))
+	}
+
+	return ids, nil
+}
+
+// groupMSISDNs pages through the contacts of a group and adds their MSISDNs
+// to msisdns.
+func groupMSISDNs(c messagebird.Client, groupID string, msisdns map[string]bool) error {
+	for offset := 0; ; {
+		contacts, err := group.ListContacts(c, groupID, &messagebird.PaginationRequest{Limit: listPageSize, Offset: offset})
+		if err != nil {
+			return err
+		}
+
+		for _, ct := range contacts.Items {
+			msisdns[strconv.FormatInt(ct.MSISDN, 10)] = true
+		}
+
+		offset += len(contacts.Items)
+		if len(contacts.Items) == 0 || offset >= contacts.TotalCount {
+			return nil
+		}
+	}
+}
+
+// unique removes duplicates from ids, keeping their order.
+func unique(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	result := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
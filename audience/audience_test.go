@@ -0,0 +1,133 @@
+package audience
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	mbtest.EnableServer(m)
+}
+
+// willReturnAudienceTestdata sets up two groups, "Customers" with members
+// 31600000000 to 31600000119 and "Staff" with 31612345678 and 31600000001,
+// and contacts whose ID is their MSISDN. Groups are listed one per page to
+// exercise the paging.
+func willReturnAudienceTestdata(t *testing.T) {
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/groups?limit=100&offset=0", "groupListPage1Object.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/groups?limit=100&offset=1", "groupListPage2Object.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/groups/customers-id/contacts?limit=100&offset=0", "customersContactListPage1Object.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/groups/customers-id/contacts?limit=100&offset=100", "customersContactListPage2Object.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/groups/staff-id/contacts?limit=100&offset=0", "staffContactListObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/contacts/31687654321", "contactObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/contacts/31612345678", "staffContactObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/contacts/unknown", "contactNotFoundError.json", http.StatusNotFound)
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/messages", "messageObject.json", http.StatusCreated)
+	mbtest.ResetRequests()
+}
+
+// sentRecipients gets the recipients of the messages created since
+// willReturnAudienceTestdata.
+func sentRecipients(t *testing.T) [][]string {
+	var sent [][]string
+	for _, request := range mbtest.Requests() {
+		if request.Method != http.MethodPost {
+			continue
+		}
+
+		var data struct {
+			Recipients []string `json:"recipients"`
+		}
+		assert.NoError(t, json.Unmarshal(request.Body, &data))
+		sent = append(sent, data.Recipients)
+	}
+
+	return sent
+}
+
+func TestResolve(t *testing.T) {
+	willReturnAudienceTestdata(t)
+	client := mbtest.Client(t)
+
+	resolution, err := Resolve(client, &Audience{
+		GroupNames: []string{"Staff"},
+		ContactIDs: []string{"31687654321", "31612345678"},
+		MSISDNs:    []string{"+31611111111", "31611111111"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"staff-id"}, resolution.GroupIDs)
+	assert.Equal(t, []string{"31600000001", "31611111111", "31612345678", "31687654321"}, resolution.MSISDNs)
+	assert.Equal(t, 4, resolution.Count())
+
+	// Two pages of groups, the Staff members and two contacts.
+	assert.Len(t, mbtest.Requests(), 5)
+	assert.Empty(t, sentRecipients(t))
+}
+
+func TestResolveUnknownGroupName(t *testing.T) {
+	willReturnAudienceTestdata(t)
+	client := mbtest.Client(t)
+
+	_, err := Resolve(client, &Audience{GroupNames: []string{"Staff", "Suppliers", "Partners"}})
+	assert.EqualError(t, err, "no groups found named Partners, Suppliers")
+}
+
+func TestResolveUnknownContact(t *testing.T) {
+	willReturnAudienceTestdata(t)
+	client := mbtest.Client(t)
+
+	_, err := Resolve(client, &Audience{ContactIDs: []string{"unknown"}})
+	assert.EqualError(t, err, "could not read contact unknown: API errors: contact not found")
+}
+
+func TestSend(t *testing.T) {
+	willReturnAudienceTestdata(t)
+	client := mbtest.Client(t)
+
+	result, err := Send(client, "TestOrg", "Hello", &Audience{
+		GroupNames: []string{"Customers"},
+		GroupIDs:   []string{"staff-id", "customers-id"},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"staff-id", "customers-id"}, result.GroupIDs)
+
+	// 31600000001 is in both groups.
+	assert.Equal(t, 121, result.Count())
+
+	assert.Len(t, result.Messages, 3)
+	assert.Equal(t, "6fe65f90454aa61536e6a88b88972670", result.Messages[2].ID)
+
+	sent := sentRecipients(t)
+	assert.Len(t, sent, 3)
+	assert.Len(t, sent[0], 50)
+	assert.Len(t, sent[1], 50)
+	assert.Len(t, sent[2], 21)
+	assert.Equal(t, result.MSISDNs[100:], sent[2])
+}
+
+func TestSendDryRun(t *testing.T) {
+	willReturnAudienceTestdata(t)
+	client := mbtest.Client(t)
+
+	result, err := Send(client, "TestOrg", "Hello", &Audience{GroupNames: []string{"Staff"}}, &SendOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"31600000001", "31612345678"}, result.MSISDNs)
+	assert.Empty(t, result.Messages)
+	assert.Empty(t, sentRecipients(t))
+}
+
+func TestSendNoRecipients(t *testing.T) {
+	willReturnAudienceTestdata(t)
+	client := mbtest.Client(t)
+
+	_, err := Send(client, "TestOrg", "Hello", &Audience{}, nil)
+	assert.EqualError(t, err, "audience has no recipients")
+
+	_, err = Send(client, "TestOrg", "Hello", nil, nil)
+	assert.EqualError(t, err, "audience is required")
+	assert.Empty(t, mbtest.Requests())
+}
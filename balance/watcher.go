@@ -0,0 +1,223 @@
+package balance
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// EventType indicates why a Watcher emitted an Event.
+type EventType string
+
+const (
+	// EventBelowThreshold is emitted when the balance drops below a
+	// threshold. It is also emitted for every threshold the balance is below
+	// when the Watcher reads the balance for the first time.
+	EventBelowThreshold EventType = "below_threshold"
+
+	// EventAboveThreshold is emitted when the balance rises to or above a
+	// threshold it was below before, e.g. after topping up.
+	EventAboveThreshold EventType = "above_threshold"
+
+	// EventRapidDrop is emitted when the balance dropped faster than the
+	// configured maximum rate since the previous read.
+	EventRapidDrop EventType = "rapid_drop"
+
+	// EventError is emitted when the balance could not be read.
+	EventError EventType = "error"
+)
+
+// Event is emitted by a Watcher.
+type Event struct {
+	Type EventType
+
+	// Balance is the balance that triggered the event. It is nil for
+	// EventError.
+	Balance *Balance
+
+	// Previous is the balance that was read before Balance. It is nil on the
+	// first read.
+	Previous *Balance
+
+	// Threshold is the threshold that was crossed, for EventBelowThreshold
+	// and EventAboveThreshold.
+	Threshold float32
+
+	// DropRate is the amount the balance dropped per hour, for
+	// EventRapidDrop.
+	DropRate float32
+
+	// Err is the error that occurred, for EventError.
+	Err error
+
+	// Time is when the balance was read.
+	Time time.Time
+}
+
+// Watcher polls the balance on an interval and emits events when it crosses
+// thresholds or drops too fast. It can be used to e.g. pause campaigns before
+// prepaid credit runs out.
+type Watcher struct {
+	client      messagebird.Client
+	interval    time.Duration
+	thresholds  []float32
+	maxDropRate float32
+
+	// maxDrop and maxDropPer are set by WithMaxDropRate, and checked and
+	// turned into maxDropRate by NewWatcher.
+	maxDrop    float32
+	maxDropPer time.Duration
+
+	mu     sync.Mutex
+	last   *Balance
+	lastAt time.Time
+
+	// now timestamps reads, which the drop rate is calculated from.
+	now func() time.Time
+}
+
+// WatcherOption configures a Watcher.
+type WatcherOption func(*Watcher)
+
+// WithThresholds sets the amounts the Watcher emits EventBelowThreshold and
+// EventAboveThreshold for.
+func WithThresholds(thresholds ...float32) WatcherOption {
+	return func(w *Watcher) {
+		w.thresholds = append(w.thresholds, thresholds...)
+		sort.Slice(w.thresholds, func(i, j int) bool {
+			return w.thresholds[i] > w.thresholds[j]
+		})
+	}
+}
+
+// WithMaxDropRate makes the Watcher emit EventRapidDrop when the balance drops
+// by more than amount per duration between two reads.
+func WithMaxDropRate(amount float32, per time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.maxDrop, w.maxDropPer = amount, per
+	}
+}
+
+// NewWatcher creates a Watcher that reads the balance every interval. It
+// returns an error if interval, or the duration of WithMaxDropRate, is not
+// positive.
+func NewWatcher(c messagebird.Client, interval time.Duration, opts ...WatcherOption) (*Watcher, error) {
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	watcher := &Watcher{
+		client:   c,
+		interval: interval,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(watcher)
+	}
+
+	if watcher.maxDrop != 0 || watcher.maxDropPer != 0 {
+		if watcher.maxDropPer <= 0 {
+			return nil, errors.New("max drop rate duration must be positive")
+		}
+		watcher.maxDropRate = watcher.maxDrop / float32(watcher.maxDropPer.Hours())
+	}
+
+	return watcher, nil
+}
+
+// Watch reads the balance immediately and then every interval, until ctx is
+// done. Events are sent over the returned channel, which is closed when
+// watching stops. The channel must be drained: no new reads are done while an
+// event is waiting to be received.
+func (w *Watcher) Watch(ctx context.Context) <-chan Event {
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			for _, event := range w.poll() {
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Last returns the most recently read balance and when it was read. It
+// returns nil if the balance has not been read yet.
+func (w *Watcher) Last() (*Balance, time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.last == nil {
+		return nil, time.Time{}
+	}
+
+	balance := *w.last
+
+	return &balance, w.lastAt
+}
+
+// poll reads the balance and returns the events it triggers.
+func (w *Watcher) poll() []Event {
+	balance, err := Read(w.client)
+	at := w.now()
+	if err != nil {
+		return []Event{{Type: EventError, Err: err, Time: at}}
+	}
+
+	// Events hand out balance, so keep a copy that can't be modified.
+	last := *balance
+
+	w.mu.Lock()
+	previous, previousAt := w.last, w.lastAt
+	w.last, w.lastAt = &last, at
+	w.mu.Unlock()
+
+	return w.check(previous, previousAt, balance, at)
+}
+
+// check compares the balance read at the given time to the previous one. The
+// previous balance is nil on the first read.
+func (w *Watcher) check(previous *Balance, previousAt time.Time, current *Balance, at time.Time) []Event {
+	var events []Event
+
+	for _, threshold := range w.thresholds {
+		switch {
+		case current.Amount < threshold && (previous == nil || previous.Amount >= threshold):
+			events = append(events, Event{Type: EventBelowThreshold, Balance: current, Previous: previous, Threshold: threshold, Time: at})
+		case current.Amount >= threshold && previous != nil && previous.Amount < threshold:
+			events = append(events, Event{Type: EventAboveThreshold, Balance: current, Previous: previous, Threshold: threshold, Time: at})
+		}
+	}
+
+	elapsed := at.Sub(previousAt)
+	if w.maxDropRate > 0 && previous != nil && elapsed > 0 && current.Amount < previous.Amount {
+		rate := (previous.Amount - current.Amount) / float32(elapsed.Hours())
+		if rate > w.maxDropRate {
+			events = append(events, Event{Type: EventRapidDrop, Balance: current, Previous: previous, DropRate: rate, Time: at})
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,118 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcherCheck(t *testing.T) {
+	watcher, err := NewWatcher(nil, time.Minute, WithThresholds(5, 10), WithMaxDropRate(1, time.Hour))
+	assert.NoError(t, err)
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The first read reports every threshold the balance is below.
+	events := watcher.check(nil, time.Time{}, &Balance{Amount: 4}, start)
+	assert.Len(t, events, 2)
+	assert.Equal(t, EventBelowThreshold, events[0].Type)
+	assert.EqualValues(t, 10, events[0].Threshold)
+	assert.Equal(t, EventBelowThreshold, events[1].Type)
+	assert.EqualValues(t, 5, events[1].Threshold)
+
+	events = watcher.check(&Balance{Amount: 4}, start, &Balance{Amount: 20}, start.Add(time.Hour))
+	assert.Len(t, events, 2)
+	assert.Equal(t, EventAboveThreshold, events[0].Type)
+	assert.EqualValues(t, 10, events[0].Threshold)
+	assert.Equal(t, EventAboveThreshold, events[1].Type)
+	assert.EqualValues(t, 5, events[1].Threshold)
+
+	// Dropping 0.5 in an hour is within the allowed rate.
+	events = watcher.check(&Balance{Amount: 20}, start, &Balance{Amount: 19.5}, start.Add(time.Hour))
+	assert.Empty(t, events)
+
+	// Dropping 11 in 30 minutes is not, and crosses a threshold.
+	events = watcher.check(&Balance{Amount: 20}, start, &Balance{Amount: 9}, start.Add(30*time.Minute))
+	assert.Len(t, events, 2)
+	assert.Equal(t, EventBelowThreshold, events[0].Type)
+	assert.EqualValues(t, 10, events[0].Threshold)
+	assert.Equal(t, EventRapidDrop, events[1].Type)
+	assert.EqualValues(t, 22, events[1].DropRate)
+}
+
+func TestWatcherWatch(t *testing.T) {
+	amounts := []float32{12, 8, 8, 3}
+	var requests int32
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(atomic.AddInt32(&requests, 1)) - 1
+		if i >= len(amounts) {
+			i = len(amounts) - 1
+		}
+
+		_, err := fmt.Fprintf(w, `{"payment":"prepaid","type":"credits","amount":%g}`, amounts[i])
+		assert.NoError(t, err)
+	})
+	transport, teardown := mbtest.HTTPTestTransport(h)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	watcher, err := NewWatcher(client, time.Millisecond, WithThresholds(10, 5))
+	assert.NoError(t, err)
+
+	balance, _ := watcher.Last()
+	assert.Nil(t, balance)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := watcher.Watch(ctx)
+
+	event := <-events
+	assert.Equal(t, EventBelowThreshold, event.Type)
+	assert.EqualValues(t, 10, event.Threshold)
+	assert.EqualValues(t, 12, event.Previous.Amount)
+	assert.EqualValues(t, 8, event.Balance.Amount)
+
+	event = <-events
+	assert.Equal(t, EventBelowThreshold, event.Type)
+	assert.EqualValues(t, 5, event.Threshold)
+	assert.EqualValues(t, 3, event.Balance.Amount)
+
+	balance, _ = watcher.Last()
+	assert.EqualValues(t, 3, balance.Amount)
+
+	cancel()
+	for range events {
+		// Drain until the watcher stops.
+	}
+}
+
+func TestWatcherWatchError(t *testing.T) {
+	mbtest.WillReturnAccessKeyError()
+	client := mbtest.Client(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher, err := NewWatcher(client, time.Hour)
+	assert.NoError(t, err)
+
+	event := <-watcher.Watch(ctx)
+	assert.Equal(t, EventError, event.Type)
+	assert.Error(t, event.Err)
+	assert.Nil(t, event.Balance)
+}
+
+func TestNewWatcherInvalid(t *testing.T) {
+	_, err := NewWatcher(nil, 0)
+	assert.EqualError(t, err, "interval must be positive")
+
+	_, err = NewWatcher(nil, time.Minute, WithMaxDropRate(1, 0))
+	assert.EqualError(t, err, "max drop rate duration must be positive")
+}
@@ -2,13 +2,21 @@ package contact
 
 import (
 	"net/http"
+	"net/url"
 	"time"
 
 	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/sms"
 )
 
-// path represents the path to the Contacts resource.
-const path = "contacts"
+const (
+	// path represents the path to the Contacts resource.
+	path = "contacts"
+
+	// messagesPath represents the path to the Messages resource within
+	// Contacts.
+	messagesPath = "messages"
+)
 
 // Contact gets returned by the API.
 type Contact struct {
@@ -17,13 +25,8 @@ type Contact struct {
 	MSISDN        int64
 	FirstName     string
 	LastName      string
-	CustomDetails struct {
-		Custom1 string
-		Custom2 string
-		Custom3 string
-		Custom4 string
-	}
-	Groups struct {
+	CustomDetails CustomDetails
+	Groups        struct {
 		TotalCount int
 		HRef       string
 	}
@@ -35,6 +38,15 @@ type Contact struct {
 	UpdatedDatetime *time.Time
 }
 
+// CustomDetails holds the custom fields of a contact. Decode and Encode can be
+// used to bind them to a struct.
+type CustomDetails struct {
+	Custom1 string
+	Custom2 string
+	Custom3 string
+	Custom4 string
+}
+
 type Contacts struct {
 	Limit, Offset     int
 	Count, TotalCount int
@@ -58,6 +70,71 @@ type ViewRequest struct {
 	Name   string `json:"firstName,omitempty"`
 }
 
+// QueryParams encodes the filters of the request as query parameters. Name is
+// sent as firstName, like the field of the contact.
+func (vr *ViewRequest) QueryParams() string {
+	if vr == nil {
+		return ""
+	}
+
+	query := url.Values{}
+
+	if vr.MSISDN != "" {
+		query.Set("msisdn", vr.MSISDN)
+	}
+	if vr.Name != "" {
+		query.Set("firstName", vr.Name)
+	}
+
+	return query.Encode()
+}
+
+// ListOptions are the options List accepts: a *messagebird.PaginationRequest
+// to page through all contacts, or a *ListRequest to also filter them.
+type ListOptions interface {
+	QueryParams() string
+}
+
+// ListRequest contains the pagination and filters for List. The filters are
+// the query parameters of the List contacts endpoint, see
+// https://developers.messagebird.com/api/contacts/#list-contacts.
+type ListRequest struct {
+	messagebird.PaginationRequest
+	MSISDN string
+
+	// Name filters on the first name of the contacts.
+	Name string
+
+	// CreatedFrom and CreatedUntil limit the results to contacts created in
+	// the given period. Either can be nil.
+	CreatedFrom  *time.Time
+	CreatedUntil *time.Time
+}
+
+// QueryParams encodes the pagination and filters of the request as query
+// parameters. Name is sent as firstName, as for ViewRequest.
+func (lr *ListRequest) QueryParams() string {
+	if lr == nil {
+		return ""
+	}
+
+	query, _ := url.ParseQuery(lr.PaginationRequest.QueryParams())
+	if lr.MSISDN != "" {
+		query.Set("msisdn", lr.MSISDN)
+	}
+	if lr.Name != "" {
+		query.Set("firstName", lr.Name)
+	}
+	if lr.CreatedFrom != nil {
+		query.Set("createdDatetimeFrom", lr.CreatedFrom.Format(time.RFC3339))
+	}
+	if lr.CreatedUntil != nil {
+		query.Set("createdDatetimeUntil", lr.CreatedUntil.Format(time.RFC3339))
+	}
+
+	return query.Encode()
+}
+
 func Create(c messagebird.Client, contactRequest *CreateRequest) (*Contact, error) {
 	contact := &Contact{}
 	if err := c.Request(contact, http.MethodPost, path, contactRequest); err != nil {
@@ -74,10 +151,16 @@ func Delete(c messagebird.Client, id string) error {
 }
 
 // List retrieves a paginated list of contacts, based on the options provided.
-// It's worth noting DefaultListOptions.
-func List(c messagebird.Client, options *messagebird.PaginationRequest) (*Contacts, error) {
+// It's worth noting DefaultListOptions. Pass a *ListRequest to filter the
+// contacts.
+func List(c messagebird.Client, options ListOptions) (*Contacts, error) {
+	var query string
+	if options != nil {
+		query = options.QueryParams()
+	}
+
 	contactList := &Contacts{}
-	if err := c.Request(contactList, http.MethodGet, path+"?"+options.QueryParams(), nil); err != nil {
+	if err := c.Request(contactList, http.MethodGet, path+"?"+query, nil); err != nil {
 		return nil, err
 	}
 
@@ -86,14 +169,35 @@ func List(c messagebird.Client, options *messagebird.PaginationRequest) (*Contac
 
 // Read retrieves the information of an existing contact.
 func Read(c messagebird.Client, id string, req *ViewRequest) (*Contact, error) {
+	reqPath := path + "/" + id
+	if query := req.QueryParams(); query != "" {
+		reqPath += "?" + query
+	}
+
 	contact := &Contact{}
-	if err := c.Request(contact, http.MethodGet, path+"/"+id, req); err != nil {
+	if err := c.Request(contact, http.MethodGet, reqPath, nil); err != nil {
 		return nil, err
 	}
 
 	return contact, nil
 }
 
+// ListMessages retrieves a paginated list of the SMS messages sent to the
+// contact, using the contact's Messages.HRef link.
+func ListMessages(c messagebird.Client, contact *Contact, options *messagebird.PaginationRequest) (*sms.MessageList, error) {
+	href := contact.Messages.HRef
+	if href == "" {
+		href = path + "/" + contact.ID + "/" + messagesPath
+	}
+
+	messageList := &sms.MessageList{}
+	if err := c.Request(messageList, http.MethodGet, href+"?"+options.QueryParams(), nil); err != nil {
+		return nil, err
+	}
+
+	return messageList, nil
+}
+
 // Update updates the record referenced by id with any values set in contactRequest.
 // Do not set any values that should not be updated.
 func Update(c messagebird.Client, id string, contactRequest *CreateRequest) (*Contact, error) {
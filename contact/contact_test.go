@@ -158,3 +158,69 @@ func TestUpdate(t *testing.T) {
 		mbtest.AssertTestdata(t, tc.expectedTestdata, mbtest.Request.Body)
 	}
 }
+
+func TestReadWithViewRequest(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "contactObject.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	_, err := Read(client, "contact-id", &ViewRequest{MSISDN: "31612345678", Name: "Foo"})
+	assert.NoError(t, err)
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/contacts/contact-id")
+	assert.Equal(t, "firstName=Foo&msisdn=31612345678", mbtest.Request.URL.RawQuery)
+	assert.Empty(t, mbtest.Request.Body)
+}
+
+func TestListFilters(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "contactListObject.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	from := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2018, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	list, err := List(client, &ListRequest{
+		PaginationRequest: messagebird.PaginationRequest{Limit: 10, Offset: 20},
+		MSISDN:            "31612345678",
+		Name:              "Foo",
+		CreatedFrom:       &from,
+		CreatedUntil:      &until,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 2)
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/contacts")
+
+	query := mbtest.Request.URL.Query()
+	assert.Equal(t, "10", query.Get("limit"))
+	assert.Equal(t, "20", query.Get("offset"))
+	assert.Equal(t, "31612345678", query.Get("msisdn"))
+	assert.Equal(t, "Foo", query.Get("firstName"))
+	assert.Equal(t, "2018-07-01T00:00:00Z", query.Get("createdDatetimeFrom"))
+	assert.Equal(t, "2018-08-01T00:00:00Z", query.Get("createdDatetimeUntil"))
+
+	_, err = List(client, &ListRequest{MSISDN: "31612345678"})
+	assert.NoError(t, err)
+	assert.Equal(t, "msisdn=31612345678&offset=0", mbtest.Request.URL.RawQuery)
+
+	_, err = List(client, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, mbtest.Request.URL.RawQuery)
+}
+
+func TestListMessages(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "contactObject.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	contact, err := Read(client, "contact-id", nil)
+	assert.NoError(t, err)
+
+	mbtest.WillReturnTestdata(t, "contactMessageListObject.json", http.StatusOK)
+
+	list, err := ListMessages(client, contact, messagebird.DefaultPagination)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, list.TotalCount)
+	assert.Equal(t, "Hello World", list.Items[0].Body)
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/contacts/contact-id/messages")
+	assert.Equal(t, "limit=20&offset=0", mbtest.Request.URL.RawQuery)
+}
@@ -0,0 +1,190 @@
+package contact
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// customDetailsTag is the struct tag that binds a field to a custom detail,
+// e.g. `contact:"custom1"`.
+const customDetailsTag = "contact"
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Decode stores the custom details in the struct pointed to by v. Its fields
+// are bound to custom details with the "contact" struct tag:
+//
+//	type Subscription struct {
+//		Plan    string    `contact:"custom1"`
+//		Seats   int       `contact:"custom2"`
+//		Trial   bool      `contact:"custom3"`
+//		Renewal time.Time `contact:"custom4"`
+//	}
+//
+// Fields can be strings, booleans, numbers, pointers to those, or implement
+// encoding.TextUnmarshaler. Empty custom details leave the field untouched.
+func (d CustomDetails) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("custom details can only be decoded into a pointer to a struct")
+	}
+
+	return eachCustomDetailField(rv.Elem(), &d, func(field reflect.Value, name string, detail *string) error {
+		if *detail == "" {
+			return nil
+		}
+		if err := decodeCustomDetail(field, *detail); err != nil {
+			return fmt.Errorf("custom details: can not decode %s into field %s: %v", *detail, name, err)
+		}
+
+		return nil
+	})
+}
+
+// Encode sets the custom details from the tagged fields of v, which must be a
+// struct or a pointer to one. See Decode for the supported field types.
+// Custom details without a tagged field are left untouched.
+func (d *CustomDetails) Encode(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return errors.New("custom details can only be encoded from a struct")
+	}
+
+	return eachCustomDetailField(rv, d, func(field reflect.Value, name string, detail *string) error {
+		encoded, err := encodeCustomDetail(field)
+		if err != nil {
+			return fmt.Errorf("custom details: can not encode field %s: %v", name, err)
+		}
+		*detail = encoded
+
+		return nil
+	})
+}
+
+// SetCustomDetails sets the custom fields of the request. As empty fields are
+// not sent, they are not cleared when updating a contact.
+func (r *CreateRequest) SetCustomDetails(d CustomDetails) {
+	r.Custom1 = d.Custom1
+	r.Custom2 = d.Custom2
+	r.Custom3 = d.Custom3
+	r.Custom4 = d.Custom4
+}
+
+// eachCustomDetailField calls fn for every tagged field of the struct rv, with
+// the field of d it is bound to.
+func eachCustomDetailField(rv reflect.Value, d *CustomDetails, fn func(field reflect.Value, name string, detail *string) error) error {
+	details := map[string]*string{
+		"custom1": &d.Custom1,
+		"custom2": &d.Custom2,
+		"custom3": &d.Custom3,
+		"custom4": &d.Custom4,
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		tag, ok := rt.Field(i).Tag.Lookup(customDetailsTag)
+		if !ok || tag == "-" {
+			continue
+		}
+
+		detail, ok := details[strings.ToLower(tag)]
+		if !ok {
+			return fmt.Errorf("custom details: field %s has unknown tag %q", rt.Field(i).Name, tag)
+		}
+
+		if err := fn(rv.Field(i), rt.Field(i).Name, detail); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeCustomDetail(field reflect.Value, detail string) error {
+	if !field.CanSet() {
+		return errors.New("field is not exported")
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return decodeCustomDetail(field.Elem(), detail)
+	}
+
+	if reflect.PtrTo(field.Type()).Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(detail))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(detail)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(detail)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(detail, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(detail, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(detail, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
+
+func encodeCustomDetail(field reflect.Value) (string, error) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return "", nil
+		}
+		return encodeCustomDetail(field.Elem())
+	}
+
+	if field.CanInterface() {
+		// Zero times and the like are not meaningful custom details.
+		if z, ok := field.Interface().(interface{ IsZero() bool }); ok && z.IsZero() {
+			return "", nil
+		}
+		if m, ok := field.Interface().(encoding.TextMarshaler); ok {
+			b, err := m.MarshalText()
+			return string(b), err
+		}
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, field.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", field.Type())
+	}
+}
@@ -0,0 +1,75 @@
+package contact
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type subscription struct {
+	Plan    string    `contact:"custom1"`
+	Seats   int       `contact:"custom2"`
+	Trial   *bool     `contact:"custom3"`
+	Renewal time.Time `contact:"custom4"`
+	Note    string    `contact:"-"`
+	Ignored string
+}
+
+func TestCustomDetailsDecode(t *testing.T) {
+	details := CustomDetails{
+		Custom1: "pro",
+		Custom2: "12",
+		Custom3: "true",
+		Custom4: "2022-03-01T00:00:00Z",
+	}
+
+	s := &subscription{Note: "keep"}
+	err := details.Decode(s)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "pro", s.Plan)
+	assert.Equal(t, 12, s.Seats)
+	assert.True(t, *s.Trial)
+	assert.True(t, s.Renewal.Equal(time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "keep", s.Note)
+
+	// Empty custom details leave fields untouched.
+	s = &subscription{Plan: "free"}
+	err = CustomDetails{}.Decode(s)
+	assert.NoError(t, err)
+	assert.Equal(t, "free", s.Plan)
+	assert.Nil(t, s.Trial)
+}
+
+func TestCustomDetailsDecodeError(t *testing.T) {
+	err := CustomDetails{Custom2: "many"}.Decode(&subscription{})
+	assert.EqualError(t, err, `custom details: can not decode many into field Seats: strconv.ParseInt: parsing "many": invalid syntax`)
+
+	err = CustomDetails{}.Decode(subscription{})
+	assert.Error(t, err)
+
+	err = CustomDetails{}.Decode(&struct {
+		Plan string `contact:"custom5"`
+	}{})
+	assert.EqualError(t, err, `custom details: field Plan has unknown tag "custom5"`)
+}
+
+func TestCustomDetailsEncode(t *testing.T) {
+	trial := false
+	details := CustomDetails{Custom4: "untouched"}
+
+	err := details.Encode(subscription{Plan: "pro", Seats: 3, Trial: &trial})
+	assert.NoError(t, err)
+	assert.Equal(t, CustomDetails{Custom1: "pro", Custom2: "3", Custom3: "false"}, details)
+
+	err = details.Encode(&subscription{Renewal: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	assert.Equal(t, "2022-03-01T00:00:00Z", details.Custom4)
+
+	req := &CreateRequest{MSISDN: "31612345678"}
+	req.SetCustomDetails(details)
+	assert.Equal(t, "", req.Custom1)
+	assert.Equal(t, "0", req.Custom2)
+	assert.Equal(t, "2022-03-01T00:00:00Z", req.Custom4)
+}
@@ -0,0 +1,131 @@
+package contact
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// DefaultExportPageSize is the number of contacts Export requests per page
+// when no page size is given.
+const DefaultExportPageSize = 100
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	Format Format
+
+	// Filter limits the exported contacts. Its pagination fields are ignored.
+	// All contacts are exported when it is nil.
+	Filter *ListRequest
+
+	// PageSize is the number of contacts requested per page.
+	// DefaultExportPageSize is used when it is zero.
+	PageSize int
+}
+
+// Export pages through all contacts and writes them to w, in a format that
+// Import can read. It returns the number of exported contacts.
+func Export(c messagebird.Client, w io.Writer, options *ExportOptions) (int, error) {
+	if options == nil {
+		options = &ExportOptions{}
+	}
+
+	writer, err := newRecordWriter(w, options.Format)
+	if err != nil {
+		return 0, err
+	}
+
+	pageSize := options.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultExportPageSize
+	}
+
+	exported := 0
+	for offset := 0; ; {
+		page, err := listPage(c, options.Filter, messagebird.PaginationRequest{Limit: pageSize, Offset: offset})
+		if err != nil {
+			return exported, err
+		}
+
+		for i := range page.Items {
+			if err := writer.write(newRecord(&page.Items[i])); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		offset += len(page.Items)
+		if len(page.Items) == 0 || offset >= page.TotalCount {
+			break
+		}
+	}
+
+	return exported, writer.flush()
+}
+
+// listPage gets a single page of the contacts that match filter.
+func listPage(c messagebird.Client, filter *ListRequest, pagination messagebird.PaginationRequest) (*Contacts, error) {
+	request := ListRequest{}
+	if filter != nil {
+		request = *filter
+	}
+	request.PaginationRequest = pagination
+
+	return List(c, &request)
+}
+
+func newRecord(contact *Contact) *Record {
+	return &Record{
+		ID:        contact.ID,
+		MSISDN:    strconv.FormatInt(contact.MSISDN, 10),
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+		Custom1:   contact.CustomDetails.Custom1,
+		Custom2:   contact.CustomDetails.Custom2,
+		Custom3:   contact.CustomDetails.Custom3,
+		Custom4:   contact.CustomDetails.Custom4,
+	}
+}
+
+// recordWriter writes records in a single Format.
+type recordWriter struct {
+	write func(*Record) error
+	flush func() error
+}
+
+func newRecordWriter(w io.Writer, format Format) (*recordWriter, error) {
+	switch format {
+	case FormatCSV, "":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvColumns); err != nil {
+			return nil, err
+		}
+
+		return &recordWriter{
+			write: func(r *Record) error {
+				return writer.Write([]string{r.ID, r.MSISDN, r.FirstName, r.LastName, r.Custom1, r.Custom2, r.Custom3, r.Custom4})
+			},
+			flush: func() error {
+				writer.Flush()
+				return writer.Error()
+			},
+		}, nil
+	case FormatJSONL:
+		encoder := json.NewEncoder(w)
+
+		return &recordWriter{
+			write: func(r *Record) error {
+				return encoder.Encode(r)
+			},
+			flush: func() error {
+				return nil
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
@@ -0,0 +1,53 @@
+package contact
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// export exports three contacts in two pages, and asserts both pages were
+// requested.
+func export(t *testing.T, options *ExportOptions) (string, int, error) {
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/contacts?limit=2&offset=0", "contactExportPage1Object.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/contacts?limit=2&offset=2", "contactExportPage2Object.json", http.StatusOK)
+	mbtest.ResetRequests()
+
+	buf := &bytes.Buffer{}
+	n, err := Export(mbtest.Client(t), buf, options)
+
+	var queries []string
+	for _, request := range mbtest.Requests() {
+		assert.Equal(t, "/contacts", request.URL.Path)
+		queries = append(queries, request.URL.RawQuery)
+	}
+	assert.Equal(t, []string{"limit=2&offset=0", "limit=2&offset=2"}, queries)
+
+	return buf.String(), n, err
+}
+
+func TestExportCSV(t *testing.T) {
+	out, n, err := export(t, &ExportOptions{Format: FormatCSV, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	assert.Equal(t, `id,msisdn,firstName,lastName,custom1,custom2,custom3,custom4
+first-id,31612345678,Foo,Bar,First,,,
+second-id,31687654321,"Message, Bird",,,,,
+third-id,31600000000,,,,,,
+`, out)
+}
+
+func TestExportJSONL(t *testing.T) {
+	out, n, err := export(t, &ExportOptions{Format: FormatJSONL, PageSize: 2, Filter: &ListRequest{}})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	assert.Equal(t, `{"id":"first-id","msisdn":"31612345678","firstName":"Foo","lastName":"Bar","custom1":"First"}
+{"id":"second-id","msisdn":"31687654321","firstName":"Message, Bird"}
+{"id":"third-id","msisdn":"31600000000"}
+`, out)
+}
@@ -0,0 +1,392 @@
+package contact
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/internal/pool"
+)
+
+// Format is the file format used by Import and Export.
+type Format string
+
+const (
+	// FormatCSV is comma-separated values with a header row. The columns are
+	// named after the JSON fields of Record, e.g. msisdn and firstName.
+	FormatCSV Format = "csv"
+
+	// FormatJSONL is JSON Lines: a Record JSON object per line.
+	FormatJSONL Format = "jsonl"
+)
+
+// DefaultImportConcurrency is the number of contacts Import creates or
+// updates in parallel when no concurrency is given.
+const DefaultImportConcurrency = 5
+
+// maxJSONLLineSize is the maximum length of a single JSON Lines record.
+const maxJSONLLineSize = 1024 * 1024
+
+// Record is a single contact in an import or export file.
+type Record struct {
+	ID        string `json:"id,omitempty"`
+	MSISDN    string `json:"msisdn"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Custom1   string `json:"custom1,omitempty"`
+	Custom2   string `json:"custom2,omitempty"`
+	Custom3   string `json:"custom3,omitempty"`
+	Custom4   string `json:"custom4,omitempty"`
+}
+
+// csvColumns are the columns of a CSV file, in the order Export writes them.
+var csvColumns = []string{"id", "msisdn", "firstName", "lastName", "custom1", "custom2", "custom3", "custom4"}
+
+// fields gets pointers to the record's fields, keyed by lower case column
+// name.
+func (r *Record) fields() map[string]*string {
+	return map[string]*string{
+		"id":        &r.ID,
+		"msisdn":    &r.MSISDN,
+		"firstname": &r.FirstName,
+		"lastname":  &r.LastName,
+		"custom1":   &r.Custom1,
+		"custom2":   &r.Custom2,
+		"custom3":   &r.Custom3,
+		"custom4":   &r.Custom4,
+	}
+}
+
+func (r *Record) createRequest() *CreateRequest {
+	return &CreateRequest{
+		MSISDN:    r.MSISDN,
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		Custom1:   r.Custom1,
+		Custom2:   r.Custom2,
+		Custom3:   r.Custom3,
+		Custom4:   r.Custom4,
+	}
+}
+
+// ImportOptions configures Import.
+type ImportOptions struct {
+	Format Format
+
+	// Concurrency is the maximum number of contacts that are created or
+	// updated in parallel. DefaultImportConcurrency is used when it is zero.
+	Concurrency int
+
+	// MatchID updates the contact with the ID of a row, instead of the
+	// contact with its MSISDN. IDs only exist in the account they were
+	// exported from, so only set it to import an export back into the same
+	// account. Rows without an ID are still matched on MSISDN.
+	MatchID bool
+}
+
+// ImportAction describes what Import did with a row.
+type ImportAction string
+
+const (
+	ImportActionCreated ImportAction = "created"
+	ImportActionUpdated ImportAction = "updated"
+	ImportActionSkipped ImportAction = "skipped"
+	ImportActionFailed  ImportAction = "failed"
+)
+
+// RowError is the error for a single row of an import file.
+type RowError struct {
+	// Row is the 1-based number of the record in the file, not counting the
+	// CSV header.
+	Row    int
+	MSISDN string
+	Err    error
+}
+
+// Error implements error interface.
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// ErrDuplicateMSISDN is reported for rows with an MSISDN that was already
+// imported from an earlier row.
+var ErrDuplicateMSISDN = errors.New("duplicate msisdn")
+
+// ImportReport summarizes an import.
+type ImportReport struct {
+	Created int
+	Updated int
+	Skipped int
+	Failed  int
+
+	// Errors holds an error for every skipped and failed row, ordered by row.
+	Errors []*RowError
+}
+
+func (r *ImportReport) add(action ImportAction, err *RowError) {
+	switch action {
+	case ImportActionCreated:
+		r.Created++
+	case ImportActionUpdated:
+		r.Updated++
+	case ImportActionSkipped:
+		r.Skipped++
+	case ImportActionFailed:
+		r.Failed++
+	}
+
+	if err != nil {
+		r.Errors = append(r.Errors, err)
+	}
+}
+
+// importRow is a record read from an import file.
+type importRow struct {
+	number int
+	record *Record
+	err    error
+}
+
+// Import reads contacts from r and creates or updates them. Rows are read as
+// a stream, so files of any size can be imported.
+//
+// An existing contact with the same MSISDN is updated, or a new contact is
+// created. The ID of a row is ignored unless MatchID is set, so an export of
+// one account can be imported into another. Rows with an MSISDN
+// that already occurred in the file are skipped. Errors for individual rows
+// don't stop the import: they are listed in the report. An error is only
+// returned if the file can't be read at all.
+func Import(c messagebird.Client, r io.Reader, options *ImportOptions) (*ImportReport, error) {
+	if options == nil {
+		options = &ImportOptions{}
+	}
+
+	rows, err := readRecords(r, options.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultImportConcurrency
+	}
+
+	report := &ImportReport{}
+	var mu sync.Mutex
+
+	seen := make(map[string]bool)
+	workers := pool.New(concurrency)
+
+	for row := range rows {
+		var rowErr *RowError
+		var key string
+		if row.record != nil {
+			key = strings.TrimPrefix(row.record.MSISDN, "+")
+		}
+
+		switch {
+		case row.err != nil:
+			rowErr = &RowError{Row: row.number, Err: row.err}
+		case row.record.MSISDN == "":
+			rowErr = &RowError{Row: row.number, Err: errors.New("msisdn is required")}
+		case seen[key]:
+			rowErr = &RowError{Row: row.number, MSISDN: row.record.MSISDN, Err: ErrDuplicateMSISDN}
+		}
+
+		if rowErr != nil {
+			mu.Lock()
+			report.add(ImportActionSkipped, rowErr)
+			mu.Unlock()
+			continue
+		}
+
+		seen[key] = true
+		row := row
+		workers.Go(func() {
+			action, err := importRecord(c, row.record, options.MatchID)
+
+			mu.Lock()
+			if err != nil {
+				report.add(action, &RowError{Row: row.number, MSISDN: row.record.MSISDN, Err: err})
+			} else {
+				report.add(action, nil)
+			}
+			mu.Unlock()
+		})
+	}
+
+	workers.Wait()
+
+	sort.Slice(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Row < report.Errors[j].Row
+	})
+
+	return report, nil
+}
+
+// importRecord creates or updates the contact for record. The ID of record is
+// only used if matchID is set.
+func importRecord(c messagebird.Client, record *Record, matchID bool) (ImportAction, error) {
+	var id string
+	if matchID {
+		id = record.ID
+	}
+	if id == "" {
+		existing, err := findByMSISDN(c, record.MSISDN)
+		if err != nil {
+			return ImportActionFailed, err
+		}
+		if existing != nil {
+			id = existing.ID
+		}
+	}
+
+	if id == "" {
+		if _, err := Create(c, record.createRequest()); err != nil {
+			return ImportActionFailed, err
+		}
+
+		return ImportActionCreated, nil
+	}
+
+	if _, err := Update(c, id, record.createRequest()); err != nil {
+		return ImportActionFailed, err
+	}
+
+	return ImportActionUpdated, nil
+}
+
+// findByMSISDN returns the contact with the given MSISDN, or nil if there is
+// no such contact.
+func findByMSISDN(c messagebird.Client, msisdn string) (*Contact, error) {
+	number, err := strconv.ParseInt(strings.TrimPrefix(msisdn, "+"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid msisdn %s", msisdn)
+	}
+
+	contacts, err := List(c, &ListRequest{
+		PaginationRequest: messagebird.PaginationRequest{Limit: 1},
+		MSISDN:            msisdn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range contacts.Items {
+		if contacts.Items[i].MSISDN == number {
+			return &contacts.Items[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// readRecords streams the records in r over the returned channel.
+func readRecords(r io.Reader, format Format) (<-chan *importRow, error) {
+	switch format {
+	case FormatCSV, "":
+		return readCSVRecords(r)
+	case FormatJSONL:
+		return readJSONLRecords(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+func readCSVRecords(r io.Reader) (<-chan *importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV header: %v", err)
+	}
+
+	columns := make([]string, len(header))
+	hasMSISDN := false
+	for i, name := range header {
+		columns[i] = strings.ToLower(strings.TrimSpace(name))
+		hasMSISDN = hasMSISDN || columns[i] == "msisdn"
+	}
+	if !hasMSISDN {
+		return nil, errors.New("CSV header has no msisdn column")
+	}
+
+	out := make(chan *importRow)
+	go func() {
+		defer close(out)
+		for number := 1; ; number++ {
+			values, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				out <- &importRow{number: number, err: err}
+
+				// The reader can continue after malformed rows, but not
+				// after other errors.
+				if _, ok := err.(*csv.ParseError); ok {
+					continue
+				}
+				return
+			}
+
+			record := &Record{}
+			fields := record.fields()
+			for i, value := range values {
+				if i >= len(columns) {
+					break
+				}
+				if field, ok := fields[columns[i]]; ok {
+					*field = strings.TrimSpace(value)
+				}
+			}
+
+			out <- &importRow{number: number, record: record}
+		}
+	}()
+
+	return out, nil
+}
+
+func readJSONLRecords(r io.Reader) <-chan *importRow {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineSize)
+
+	out := make(chan *importRow)
+	go func() {
+		defer close(out)
+
+		number := 0
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			number++
+			record := &Record{}
+			if err := json.Unmarshal([]byte(line), record); err != nil {
+				out <- &importRow{number: number, err: err}
+				continue
+			}
+
+			out <- &importRow{number: number, record: record}
+		}
+
+		if err := scanner.Err(); err != nil {
+			out <- &importRow{number: number + 1, err: err}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,131 @@
+package contact
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// willReturnImportTestdata sets up the responses for imports: 31612345678
+// is an existing contact, looking up 31600000000 fails and other numbers are
+// new contacts.
+func willReturnImportTestdata(t *testing.T) {
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/contacts", "contactListEmptyObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/contacts?limit=1&msisdn=31612345678&offset=0", "contactListByMSISDNObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/contacts?limit=1&msisdn=31600000000&offset=0", "contactInvalidMSISDNError.json", http.StatusUnprocessableEntity)
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/contacts", "contactObject.json", http.StatusCreated)
+	mbtest.WillReturnTestdataFor(t, http.MethodPatch, "/contacts/existing-id", "contactObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodPatch, "/contacts/contact-id", "contactObject.json", http.StatusOK)
+	mbtest.ResetRequests()
+}
+
+// importWrites gets the create and update requests made since
+// willReturnImportTestdata, sorted, as rows are imported in parallel.
+func importWrites() []string {
+	var writes []string
+	for _, request := range mbtest.Requests() {
+		if request.Method != http.MethodGet {
+			writes = append(writes, request.Method+" "+request.URL.Path+" "+string(request.Body))
+		}
+	}
+	sort.Strings(writes)
+
+	return writes
+}
+
+func TestImportCSV(t *testing.T) {
+	willReturnImportTestdata(t)
+	client := mbtest.Client(t)
+
+	report, err := Import(client, strings.NewReader(strings.Join([]string{
+		"MSISDN,firstName,lastName,custom1,unknown",
+		"31612345678,Foo,Bar,First,ignored",
+		"31687654321,Message,Bird,,",
+		"+31687654321,Duplicate,,,",
+		",No,MSISDN,,",
+		"31600000000,Invalid,,,",
+		`"broken,quote`,
+	}, "\n")), &ImportOptions{Format: FormatCSV, Concurrency: 2})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 3, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+
+	assert.Len(t, report.Errors, 4)
+	assert.Equal(t, 3, report.Errors[0].Row)
+	assert.Equal(t, ErrDuplicateMSISDN, report.Errors[0].Err)
+	assert.EqualError(t, report.Errors[1], "row 4: msisdn is required")
+	assert.EqualError(t, report.Errors[2], "row 5: API errors: msisdn is invalid")
+	assert.Equal(t, "31600000000", report.Errors[2].MSISDN)
+	assert.Equal(t, 6, report.Errors[3].Row)
+
+	assert.Equal(t, []string{
+		`PATCH /contacts/existing-id {"msisdn":"31612345678","firstName":"Foo","lastName":"Bar","custom1":"First"}`,
+		`POST /contacts {"msisdn":"31687654321","firstName":"Message","lastName":"Bird"}`,
+	}, importWrites())
+}
+
+func TestImportJSONL(t *testing.T) {
+	willReturnImportTestdata(t)
+	client := mbtest.Client(t)
+
+	report, err := Import(client, strings.NewReader(strings.Join([]string{
+		`{"id":"contact-id","msisdn":"31612345678","firstName":"Foo"}`,
+		``,
+		`{"msisdn":"31687654321"}`,
+		`not json`,
+	}, "\n")), &ImportOptions{Format: FormatJSONL})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Len(t, report.Errors, 1)
+	assert.Equal(t, 3, report.Errors[0].Row)
+	_, ok := report.Errors[0].Err.(*json.SyntaxError)
+	assert.True(t, ok)
+
+	// The ID is ignored: the contact is matched on MSISDN.
+	assert.Equal(t, []string{
+		`PATCH /contacts/existing-id {"msisdn":"31612345678","firstName":"Foo"}`,
+		`POST /contacts {"msisdn":"31687654321"}`,
+	}, importWrites())
+}
+
+func TestImportMatchID(t *testing.T) {
+	willReturnImportTestdata(t)
+	client := mbtest.Client(t)
+
+	report, err := Import(client, strings.NewReader(strings.Join([]string{
+		`{"id":"contact-id","msisdn":"31612345678","firstName":"Foo"}`,
+		`{"msisdn":"31687654321"}`,
+	}, "\n")), &ImportOptions{Format: FormatJSONL, MatchID: true})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, []string{
+		`PATCH /contacts/contact-id {"msisdn":"31612345678","firstName":"Foo"}`,
+		`POST /contacts {"msisdn":"31687654321"}`,
+	}, importWrites())
+}
+
+func TestImportInvalidFile(t *testing.T) {
+	willReturnImportTestdata(t)
+	client := mbtest.Client(t)
+
+	_, err := Import(client, strings.NewReader("firstName,lastName\nFoo,Bar"), nil)
+	assert.EqualError(t, err, "CSV header has no msisdn column")
+
+	_, err = Import(client, strings.NewReader(""), &ImportOptions{Format: "xml"})
+	assert.EqualError(t, err, "unsupported format xml")
+
+	assert.Empty(t, importWrites())
+}
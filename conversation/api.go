@@ -11,6 +11,22 @@ const (
 	// https://conversations.messagebird.com/v1/webhooks).
 	apiRoot = "https://conversations.messagebird.com/v1"
 
+	// contactsAPIRoot is the absolute URL of the Contacts API, which manages
+	// the contacts of conversations.
+	contactsAPIRoot = "https://contacts.messagebird.com/v2"
+
+	// contactsPath is the path for the Contact resource, relative to
+	// contactsAPIRoot.
+	contactsPath = "contacts"
+
+	// integrationsAPIRoot is the absolute URL of the Integrations API, which
+	// manages WhatsApp templates.
+	integrationsAPIRoot = "https://integrations.messagebird.com/v2"
+
+	// whatsAppTemplatesPath is the path for the WhatsAppTemplate resource,
+	// relative to integrationsAPIRoot.
+	whatsAppTemplatesPath = "platforms/whatsapp/templates"
+
 	// path is the path for the Conversation resource, relative to apiRoot.
 	path = "conversations"
 
@@ -27,6 +43,9 @@ const (
 	// sendMessagePath is the path for creating the Message resource relative to apiRoot
 	sendMessagePath = "send"
 
+	// channelsPath is the path for the Channel resource, relative to apiRoot.
+	channelsPath = "channels"
+
 	// webhooksPath is the path for the Webhook resource, relative to apiRoot.
 	webhooksPath = "webhooks"
 )
@@ -37,3 +56,15 @@ const (
 func request(c messagebird.Client, v interface{}, method, path string, data interface{}) error {
 	return c.Request(v, method, fmt.Sprintf("%s/%s", apiRoot, path), data)
 }
+
+// contactsRequest is like request, but prefixes the path with the Contacts
+// API's root.
+func contactsRequest(c messagebird.Client, v interface{}, method, path string, data interface{}) error {
+	return c.Request(v, method, fmt.Sprintf("%s/%s", contactsAPIRoot, path), data)
+}
+
+// integrationsRequest is like request, but prefixes the path with the
+// Integrations API's root.
+func integrationsRequest(c messagebird.Client, v interface{}, method, path string, data interface{}) error {
+	return c.Request(v, method, fmt.Sprintf("%s/%s", integrationsAPIRoot, path), data)
+}
@@ -0,0 +1,163 @@
+package conversation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// Platform identifies the platform a channel sends messages over, e.g.
+// WhatsApp or SMS.
+type Platform string
+
+const (
+	PlatformSMS             Platform = "sms"
+	PlatformWhatsApp        Platform = "whatsapp"
+	PlatformWhatsAppSandbox Platform = "whatsapp_sandbox"
+	PlatformFacebook        Platform = "facebook"
+	PlatformInstagram       Platform = "instagram"
+	PlatformEmail           Platform = "email"
+	PlatformTelegram        Platform = "telegram"
+	PlatformLine            Platform = "line"
+	PlatformWeChat          Platform = "wechat"
+	PlatformGoogleBM        Platform = "googlebm"
+	PlatformEvents          Platform = "events"
+)
+
+const (
+	// ChannelStatusActive is the status of a channel that can send and
+	// receive messages.
+	ChannelStatusActive = "active"
+
+	// ChannelStatusInactive is the status of a channel that has been
+	// disabled.
+	ChannelStatusInactive = "inactive"
+
+	// ChannelStatusPending is the status of a channel that is being set up.
+	ChannelStatusPending = "pending"
+)
+
+// ErrChannelNotFound is returned by FindActiveChannel when no channel
+// matches.
+var ErrChannelNotFound = errors.New("no active channel found")
+
+// listChannelsPageSize is the number of channels FindActiveChannel requests
+// per page.
+const listChannelsPageSize = 50
+
+type Channel struct {
+	ID   string
+	Name string
+
+	// Deprecated: PlatformID is kept for compatibility, use Platform instead.
+	PlatformID string
+
+	// Platform is the platform the channel sends messages over. It is set
+	// from the API's platformId.
+	Platform Platform `json:"-"`
+
+	Status          string
+	CreatedDatetime *time.Time
+	UpdatedDatetime *time.Time
+}
+
+// UnmarshalJSON is used to set Platform from the platformId the API returns,
+// while keeping PlatformID a string.
+func (ch *Channel) UnmarshalJSON(data []byte) error {
+	target := struct {
+		ID              string
+		Name            string
+		PlatformID      string
+		Status          string
+		CreatedDatetime *time.Time
+		UpdatedDatetime *time.Time
+	}{}
+
+	if err := json.Unmarshal(data, &target); err != nil {
+		return err
+	}
+
+	*ch = Channel{
+		ID:              target.ID,
+		Name:            target.Name,
+		PlatformID:      target.PlatformID,
+		Platform:        Platform(target.PlatformID),
+		Status:          target.Status,
+		CreatedDatetime: target.CreatedDatetime,
+		UpdatedDatetime: target.UpdatedDatetime,
+	}
+
+	return nil
+}
+
+type ChannelList struct {
+	Offset     int
+	Limit      int
+	Count      int
+	TotalCount int
+	Items      []*Channel
+}
+
+// IsActive reports whether the channel can send messages.
+func (ch *Channel) IsActive() bool {
+	return ch.Status == ChannelStatusActive
+}
+
+// ListChannels gets a collection of the account's channels. Pagination can be
+// set in options.
+func ListChannels(c messagebird.Client, options *messagebird.PaginationRequest) (*ChannelList, error) {
+	channelList := &ChannelList{}
+	if err := request(c, channelList, http.MethodGet, channelsPath+"?"+options.QueryParams(), nil); err != nil {
+		return nil, err
+	}
+
+	return channelList, nil
+}
+
+// ReadChannel gets a single channel based on its ID.
+func ReadChannel(c messagebird.Client, id string) (*Channel, error) {
+	channel := &Channel{}
+	if err := request(c, channel, http.MethodGet, channelsPath+"/"+id, nil); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// FindActiveChannel pages through the account's channels and returns the
+// first active one on the given platform. It returns ErrChannelNotFound if
+// there is none.
+func FindActiveChannel(c messagebird.Client, platform Platform) (*Channel, error) {
+	for offset := 0; ; {
+		channels, err := ListChannels(c, &messagebird.PaginationRequest{Limit: listChannelsPageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, channel := range channels.Items {
+			if channel.Platform == platform && channel.IsActive() {
+				return channel, nil
+			}
+		}
+
+		offset += len(channels.Items)
+		if len(channels.Items) == 0 || offset >= channels.TotalCount {
+			return nil, ErrChannelNotFound
+		}
+	}
+}
+
+// LastUsedChannel gets the channel that was last used in the conversation,
+// from its Channels. It returns nil if the conversation doesn't include it.
+func (conv *Conversation) LastUsedChannel() *Channel {
+	for _, channel := range conv.Channels {
+		if channel.ID == conv.LastUsedChannelID {
+			return channel
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package conversation
+
+import (
+	"net/http"
+	"testing"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListChannels(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "channelListObject.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	channels, err := ListChannels(client, &messagebird.PaginationRequest{Limit: 20, Offset: 0})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, channels.TotalCount)
+	assert.Equal(t, PlatformSMS, channels.Items[0].Platform)
+	assert.Equal(t, "sms", channels.Items[0].PlatformID)
+	assert.False(t, channels.Items[1].IsActive())
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/v1/channels")
+	assert.Equal(t, "limit=20&offset=0", mbtest.Request.URL.RawQuery)
+}
+
+func TestReadChannel(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "channelObject.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	channel, err := ReadChannel(client, "chid")
+	assert.NoError(t, err)
+	assert.Equal(t, "chname", channel.Name)
+	assert.Equal(t, PlatformWhatsApp, channel.Platform)
+	assert.True(t, channel.IsActive())
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/v1/channels/chid")
+}
+
+func TestFindActiveChannel(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "channelListObject.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	channel, err := FindActiveChannel(client, PlatformWhatsApp)
+	assert.NoError(t, err)
+	assert.Equal(t, "waid", channel.ID)
+
+	_, err = FindActiveChannel(client, PlatformTelegram)
+	assert.Equal(t, ErrChannelNotFound, err)
+}
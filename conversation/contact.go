@@ -2,7 +2,13 @@ package conversation
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
 )
 
 type Contact struct {
@@ -11,7 +17,10 @@ type Contact struct {
 	MSISDN          string
 	FirstName       string
 	LastName        string
+	DisplayName     string
 	CustomDetails   map[string]interface{}
+	Identifiers     []*ContactIdentifier
+	Attributes      map[string]interface{}
 	CreatedDatetime *time.Time
 	UpdatedDatetime *time.Time
 }
@@ -26,7 +35,10 @@ func (c *Contact) UnmarshalJSON(data []byte) error {
 		MSISDN          json.Number
 		FirstName       string
 		LastName        string
+		DisplayName     string
 		CustomDetails   map[string]interface{}
+		Identifiers     []*ContactIdentifier
+		Attributes      map[string]interface{}
 		CreatedDatetime *time.Time
 		UpdatedDatetime *time.Time
 	}{}
@@ -47,15 +59,274 @@ func (c *Contact) UnmarshalJSON(data []byte) error {
 	}
 
 	*c = Contact{
-		target.ID,
-		target.Href,
-		target.MSISDN.String(),
-		target.FirstName,
-		target.LastName,
-		target.CustomDetails,
-		target.CreatedDatetime,
-		target.UpdatedDatetime,
+		ID:              target.ID,
+		Href:            target.Href,
+		MSISDN:          target.MSISDN.String(),
+		FirstName:       target.FirstName,
+		LastName:        target.LastName,
+		DisplayName:     target.DisplayName,
+		CustomDetails:   target.CustomDetails,
+		Identifiers:     target.Identifiers,
+		Attributes:      target.Attributes,
+		CreatedDatetime: target.CreatedDatetime,
+		UpdatedDatetime: target.UpdatedDatetime,
 	}
 
 	return nil
 }
+
+// IdentifierType is the platform a ContactIdentifier identifies a contact on.
+type IdentifierType string
+
+const (
+	IdentifierTypeMSISDN   IdentifierType = "msisdn"
+	IdentifierTypeEmail    IdentifierType = "email"
+	IdentifierTypeWhatsApp IdentifierType = "whatsapp"
+)
+
+// ContactIdentifier identifies a contact on a platform, e.g. by phone number
+// or email address.
+type ContactIdentifier struct {
+	ID    string         `json:"id,omitempty"`
+	Type  IdentifierType `json:"type"`
+	Value string         `json:"value"`
+}
+
+type ContactList struct {
+	Offset     int
+	Limit      int
+	Count      int
+	TotalCount int
+	Items      []*Contact
+}
+
+// ContactRequest contains the request data for CreateContact and
+// UpdateContact. Empty fields are not sent, so they are left untouched when
+// updating a contact.
+type ContactRequest struct {
+	DisplayName string                 `json:"displayName,omitempty"`
+	FirstName   string                 `json:"firstName,omitempty"`
+	LastName    string                 `json:"lastName,omitempty"`
+	Identifiers []*ContactIdentifier   `json:"identifiers,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// ListContactsRequest contains the pagination and filters for ListContacts.
+type ListContactsRequest struct {
+	messagebird.PaginationRequest
+
+	// Identifier only lists the contacts with an identifier with this value,
+	// e.g. a phone number or email address.
+	Identifier string
+}
+
+func (lr *ListContactsRequest) QueryParams() string {
+	if lr == nil {
+		return ""
+	}
+
+	query, _ := url.ParseQuery(lr.PaginationRequest.QueryParams())
+	if lr.Identifier != "" {
+		query.Set("identifier", lr.Identifier)
+	}
+
+	return query.Encode()
+}
+
+// Identifier gets the value of the contact's first identifier of the given
+// type. It returns an empty string if the contact has none.
+func (c *Contact) Identifier(identifierType IdentifierType) string {
+	for _, identifier := range c.Identifiers {
+		if identifier.Type == identifierType {
+			return identifier.Value
+		}
+	}
+
+	return ""
+}
+
+// CreateContact creates a new contact.
+func CreateContact(c messagebird.Client, req *ContactRequest) (*Contact, error) {
+	contact := &Contact{}
+	if err := contactsRequest(c, contact, http.MethodPost, contactsPath, req); err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+// ReadContact gets a single contact based on its ID.
+func ReadContact(c messagebird.Client, id string) (*Contact, error) {
+	contact := &Contact{}
+	if err := contactsRequest(c, contact, http.MethodGet, contactsPath+"/"+id, nil); err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+// UpdateContact updates a single contact based on its ID with any values set
+// in ContactRequest.
+func UpdateContact(c messagebird.Client, id string, req *ContactRequest) (*Contact, error) {
+	contact := &Contact{}
+	if err := contactsRequest(c, contact, http.MethodPatch, contactsPath+"/"+id, req); err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+// DeleteContact deletes a single contact based on its ID. If the error is
+// nil, the deletion was successful.
+func DeleteContact(c messagebird.Client, id string) error {
+	return contactsRequest(c, nil, http.MethodDelete, contactsPath+"/"+id, nil)
+}
+
+// ListContacts gets a collection of contacts. Pagination and filters can be
+// set in options.
+func ListContacts(c messagebird.Client, options *ListContactsRequest) (*ContactList, error) {
+	contactList := &ContactList{}
+	if err := contactsRequest(c, contactList, http.MethodGet, contactsPath+"?"+options.QueryParams(), nil); err != nil {
+		return nil, err
+	}
+
+	return contactList, nil
+}
+
+// MergeContacts merges the duplicates into the contact with the target ID.
+// Identifiers of the duplicates are added to the target, as are attributes
+// and names the target doesn't have yet.
+//
+// The names and attributes are updated first. An identifier can only belong
+// to one contact, so the duplicates are then deleted before their identifiers
+// are added to the target. If deleting or adding the identifiers fails, the
+// deleted duplicates are created again. That can't fully undo the merge: the
+// recreated contacts get new IDs, so conversations of the old contacts are not
+// linked to them. The error lists the duplicates that could not be created
+// again at all.
+func MergeContacts(c messagebird.Client, targetID string, duplicateIDs ...string) (*Contact, error) {
+	target, err := ReadContact(c, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &ContactRequest{
+		DisplayName: target.DisplayName,
+		FirstName:   target.FirstName,
+		LastName:    target.LastName,
+		Identifiers: append([]*ContactIdentifier{}, target.Identifiers...),
+		Attributes:  make(map[string]interface{}, len(target.Attributes)),
+	}
+	for key, value := range target.Attributes {
+		req.Attributes[key] = value
+	}
+
+	var duplicates []*Contact
+	for _, id := range duplicateIDs {
+		if id == targetID {
+			continue
+		}
+
+		duplicate, err := ReadContact(c, id)
+		if err != nil {
+			return nil, err
+		}
+		req.merge(duplicate)
+		duplicates = append(duplicates, duplicate)
+	}
+
+	merged, err := UpdateContact(c, targetID, &ContactRequest{
+		DisplayName: req.DisplayName,
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Attributes:  req.Attributes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Identifiers) == len(target.Identifiers) {
+		for _, duplicate := range duplicates {
+			if err := DeleteContact(c, duplicate.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		return merged, nil
+	}
+
+	for i, duplicate := range duplicates {
+		if err := DeleteContact(c, duplicate.ID); err != nil {
+			return nil, restoreContacts(c, duplicates[:i], err)
+		}
+	}
+
+	merged, err = UpdateContact(c, targetID, &ContactRequest{Identifiers: req.Identifiers})
+	if err != nil {
+		return nil, restoreContacts(c, duplicates, err)
+	}
+
+	return merged, nil
+}
+
+// restoreContacts creates the deleted contacts again after a merge failed
+// with err. It tries every contact, and returns the error to report for the
+// merge.
+func restoreContacts(c messagebird.Client, deleted []*Contact, err error) error {
+	var failures []string
+	for _, contact := range deleted {
+		req := &ContactRequest{
+			DisplayName: contact.DisplayName,
+			FirstName:   contact.FirstName,
+			LastName:    contact.LastName,
+			Attributes:  contact.Attributes,
+		}
+		for _, identifier := range contact.Identifiers {
+			req.Identifiers = append(req.Identifiers, &ContactIdentifier{Type: identifier.Type, Value: identifier.Value})
+		}
+
+		if _, createErr := CreateContact(c, req); createErr != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", contact.ID, createErr))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%v, and deleted contacts could not be created again: %s", err, strings.Join(failures, "; "))
+	}
+
+	return err
+}
+
+// merge adds the data of contact that req doesn't have yet.
+func (req *ContactRequest) merge(contact *Contact) {
+	if req.DisplayName == "" {
+		req.DisplayName = contact.DisplayName
+	}
+	if req.FirstName == "" {
+		req.FirstName = contact.FirstName
+	}
+	if req.LastName == "" {
+		req.LastName = contact.LastName
+	}
+
+	for _, identifier := range contact.Identifiers {
+		if !req.hasIdentifier(identifier) {
+			req.Identifiers = append(req.Identifiers, &ContactIdentifier{Type: identifier.Type, Value: identifier.Value})
+		}
+	}
+
+	for key, value := range contact.Attributes {
+		if _, ok := req.Attributes[key]; !ok {
+			req.Attributes[key] = value
+		}
+	}
+}
+
+func (req *ContactRequest) hasIdentifier(identifier *ContactIdentifier) bool {
+	for _, existing := range req.Identifiers {
+		if existing.Type == identifier.Type && existing.Value == identifier.Value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,10 +2,13 @@ package conversation
 
 import (
 	"encoding/json"
-	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
-	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
 	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestUnmarshalContact(t *testing.T) {
@@ -33,3 +36,190 @@ func TestUnmarshalContact(t *testing.T) {
 		c.CustomDetails,
 	)
 }
+
+func TestCreateContact(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "contactWithIdentifiers.json", http.StatusCreated)
+	client := mbtest.Client(t)
+
+	contact, err := CreateContact(client, &ContactRequest{
+		DisplayName: "Jen Smith",
+		FirstName:   "Jen",
+		LastName:    "Smith",
+		Identifiers: []*ContactIdentifier{
+			{Type: IdentifierTypeMSISDN, Value: "+31612345678"},
+			{Type: IdentifierTypeEmail, Value: "jen@example.com"},
+		},
+		Attributes: map[string]interface{}{"plan": "premium"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "contid", contact.ID)
+	assert.Equal(t, "Jen Smith", contact.DisplayName)
+	assert.Equal(t, "jen@example.com", contact.Identifier(IdentifierTypeEmail))
+	assert.Equal(t, "", contact.Identifier(IdentifierTypeWhatsApp))
+	assert.Equal(t, "premium", contact.Attributes["plan"])
+
+	mbtest.AssertEndpointCalled(t, http.MethodPost, "/v2/contacts")
+	mbtest.AssertTestdataJson(t, "contactCreateRequest.json", mbtest.Request.Body)
+}
+
+func TestReadContact(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "contactWithIdentifiers.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	contact, err := ReadContact(client, "contid")
+	assert.NoError(t, err)
+	assert.Equal(t, "+31612345678", contact.Identifier(IdentifierTypeMSISDN))
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/v2/contacts/contid")
+}
+
+func TestUpdateContact(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "contactWithIdentifiers.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	_, err := UpdateContact(client, "contid", &ContactRequest{FirstName: "Jen"})
+	assert.NoError(t, err)
+
+	mbtest.AssertEndpointCalled(t, http.MethodPatch, "/v2/contacts/contid")
+	assert.JSONEq(t, `{"firstName":"Jen"}`, string(mbtest.Request.Body))
+}
+
+func TestDeleteContact(t *testing.T) {
+	mbtest.WillReturn([]byte(""), http.StatusNoContent)
+	client := mbtest.Client(t)
+
+	err := DeleteContact(client, "contid")
+	assert.NoError(t, err)
+
+	mbtest.AssertEndpointCalled(t, http.MethodDelete, "/v2/contacts/contid")
+}
+
+func TestListContacts(t *testing.T) {
+	mbtest.WillReturn([]byte(`{"offset":0,"limit":10,"count":0,"totalCount":0,"items":[]}`), http.StatusOK)
+	client := mbtest.Client(t)
+
+	_, err := ListContacts(client, &ListContactsRequest{
+		PaginationRequest: messagebird.PaginationRequest{Limit: 10},
+		Identifier:        "+31612345678",
+	})
+	assert.NoError(t, err)
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/v2/contacts")
+	assert.Equal(t, "identifier=%2B31612345678&limit=10&offset=0", mbtest.Request.URL.RawQuery)
+}
+
+// willReturnMergeTestdata sets up a target and duplicates that share an
+// identifier, which can be deleted. The responses to updating the target and
+// creating contacts are set up by the tests.
+func willReturnMergeTestdata(t *testing.T) {
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/v2/contacts/target", "contactMergeTarget.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/v2/contacts/duplicate", "contactMergeDuplicate.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/v2/contacts/duplicate2", "contactMergeSecondDuplicate.json", http.StatusOK)
+	mbtest.WillReturnFor(t, http.MethodDelete, "/v2/contacts/duplicate", []byte(""), http.StatusNoContent)
+	mbtest.WillReturnFor(t, http.MethodDelete, "/v2/contacts/duplicate2", []byte(""), http.StatusNoContent)
+	mbtest.ResetRequests()
+}
+
+// mergeRequests gets the method and path of the requests made since
+// willReturnMergeTestdata, and the bodies of the updates of the target.
+func mergeRequests() ([]string, [][]byte) {
+	var requests []string
+	var updates [][]byte
+	for _, request := range mbtest.Requests() {
+		requests = append(requests, request.Method+" "+request.URL.Path)
+		if request.Method == http.MethodPatch {
+			updates = append(updates, request.Body)
+		}
+	}
+
+	return requests, updates
+}
+
+func TestMergeContacts(t *testing.T) {
+	willReturnMergeTestdata(t)
+	mbtest.WillReturnTestdataFor(t, http.MethodPatch, "/v2/contacts/target", "contactMergeTarget.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodPatch, "/v2/contacts/target", "contactMerged.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	merged, err := MergeContacts(client, "target", "duplicate", "target")
+	assert.NoError(t, err)
+	assert.Equal(t, "jen@example.com", merged.Identifier(IdentifierTypeEmail))
+
+	// The duplicate owns the shared identifier until it is deleted, so the
+	// identifiers are added last.
+	requests, updates := mergeRequests()
+	assert.Equal(t, []string{
+		"GET /v2/contacts/target",
+		"GET /v2/contacts/duplicate",
+		"PATCH /v2/contacts/target",
+		"DELETE /v2/contacts/duplicate",
+		"PATCH /v2/contacts/target",
+	}, requests)
+	if assert.Len(t, updates, 2) {
+		mbtest.AssertTestdataJson(t, "contactMergeAttributesRequest.json", updates[0])
+		mbtest.AssertTestdataJson(t, "contactMergeRequest.json", updates[1])
+	}
+}
+
+func TestMergeContactsUpdateFailed(t *testing.T) {
+	willReturnMergeTestdata(t)
+	mbtest.WillReturnTestdataFor(t, http.MethodPatch, "/v2/contacts/target", "contactMergeUpdateError.json", http.StatusUnprocessableEntity)
+	client := mbtest.Client(t)
+
+	_, err := MergeContacts(client, "target", "duplicate")
+	assert.EqualError(t, err, "API errors: attributes are invalid")
+
+	// Nothing is deleted.
+	requests, _ := mergeRequests()
+	assert.Equal(t, []string{
+		"GET /v2/contacts/target",
+		"GET /v2/contacts/duplicate",
+		"PATCH /v2/contacts/target",
+	}, requests)
+}
+
+func TestMergeContactsIdentifiersFailed(t *testing.T) {
+	willReturnMergeTestdata(t)
+	mbtest.WillReturnTestdataFor(t, http.MethodPatch, "/v2/contacts/target", "contactMergeTarget.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodPatch, "/v2/contacts/target", "contactMergeIdentifierError.json", http.StatusUnprocessableEntity)
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v2/contacts", "contactMergeDuplicate.json", http.StatusCreated)
+	client := mbtest.Client(t)
+
+	_, err := MergeContacts(client, "target", "duplicate", "duplicate2")
+	assert.EqualError(t, err, "API errors: identifier is invalid")
+
+	requests, _ := mergeRequests()
+	assert.Equal(t, []string{
+		"GET /v2/contacts/target",
+		"GET /v2/contacts/duplicate",
+		"GET /v2/contacts/duplicate2",
+		"PATCH /v2/contacts/target",
+		"DELETE /v2/contacts/duplicate",
+		"DELETE /v2/contacts/duplicate2",
+		"PATCH /v2/contacts/target",
+		"POST /v2/contacts",
+		"POST /v2/contacts",
+	}, requests)
+	assert.JSONEq(t, `{
+		"firstName": "Jennifer",
+		"identifiers": [{"type": "email", "value": "jen@work.example.com"}],
+		"attributes": {"company": "MessageBird"}
+	}`, string(mbtest.Request.Body))
+}
+
+func TestMergeContactsRestoreFailed(t *testing.T) {
+	willReturnMergeTestdata(t)
+	mbtest.WillReturnTestdataFor(t, http.MethodPatch, "/v2/contacts/target", "contactMergeTarget.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodPatch, "/v2/contacts/target", "contactMergeIdentifierError.json", http.StatusUnprocessableEntity)
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v2/contacts", "contactMergeUpdateError.json", http.StatusUnprocessableEntity)
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v2/contacts", "contactMergeDuplicate.json", http.StatusCreated)
+	client := mbtest.Client(t)
+
+	// A contact that can't be created again doesn't stop restoring the others.
+	_, err := MergeContacts(client, "target", "duplicate", "duplicate2")
+	assert.EqualError(t, err, "API errors: identifier is invalid, and deleted contacts could not be created again: "+
+		"duplicate: API errors: attributes are invalid")
+
+	requests, _ := mergeRequests()
+	assert.Equal(t, []string{"POST /v2/contacts", "POST /v2/contacts"}, requests[len(requests)-2:])
+}
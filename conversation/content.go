@@ -0,0 +1,174 @@
+package conversation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The functions in this file create message content of a single type. They
+// return the MessageType along with the content, so both can be passed to
+// SetContent at once:
+//
+//	req := &conversation.SendMessageRequest{To: "+31612345678", From: "channel-id"}
+//	req.SetContent(conversation.ImageMessage("https://example.com/cat.jpg", "A cat"))
+
+// TextMessage creates plain text content.
+func TextMessage(text string) (MessageType, *MessageContent) {
+	return MessageTypeText, &MessageContent{Text: text}
+}
+
+// ImageMessage creates image content from a publicly accessible URL.
+func ImageMessage(url, caption string) (MessageType, *MessageContent) {
+	return MessageTypeImage, &MessageContent{Image: &Image{URL: url, Caption: caption}}
+}
+
+// VideoMessage creates video content from a publicly accessible URL.
+func VideoMessage(url, caption string) (MessageType, *MessageContent) {
+	return MessageTypeVideo, &MessageContent{Video: &Video{URL: url, Caption: caption}}
+}
+
+// AudioMessage creates audio content from a publicly accessible URL.
+func AudioMessage(url string) (MessageType, *MessageContent) {
+	return MessageTypeAudio, &MessageContent{Audio: &Audio{URL: url}}
+}
+
+// FileMessage creates file content from a publicly accessible URL.
+func FileMessage(url, caption string) (MessageType, *MessageContent) {
+	return MessageTypeFile, &MessageContent{File: &File{URL: url, Caption: caption}}
+}
+
+// LocationMessage creates location content.
+func LocationMessage(latitude, longitude float32) (MessageType, *MessageContent) {
+	return MessageTypeLocation, &MessageContent{Location: &Location{Latitude: latitude, Longitude: longitude}}
+}
+
+// HSMMessage creates WhatsApp template content.
+func HSMMessage(hsm *HSM) (MessageType, *MessageContent) {
+	return MessageTypeHSM, &MessageContent{HSM: hsm}
+}
+
+// InteractiveMessage creates WhatsApp interactive content, e.g. buttons or a
+// list.
+func InteractiveMessage(interactive *WhatsAppInteractive) (MessageType, *MessageContent) {
+	return MessageTypeInteractive, &MessageContent{Interactive: interactive}
+}
+
+// WhatsAppStickerMessage creates WhatsApp sticker content.
+func WhatsAppStickerMessage(link string) (MessageType, *MessageContent) {
+	return MessageTypeWhatsAppSticker, &MessageContent{WhatsAppSticker: &WhatsAppSticker{Link: link}}
+}
+
+// EmailMessage creates email content.
+func EmailMessage(email *Email) (MessageType, *MessageContent) {
+	return MessageTypeEmail, &MessageContent{Email: email}
+}
+
+// ExternalAttachmentMessage creates content with one or more external
+// attachments.
+func ExternalAttachmentMessage(attachments ...*Media) (MessageType, *MessageContent) {
+	return MessageTypeExternalAttachment, &MessageContent{ExternalAttachments: attachments}
+}
+
+// FacebookQuickReplyMessage creates Facebook Messenger content with quick
+// replies.
+func FacebookQuickReplyMessage(message *FacebookMessage) (MessageType, *MessageContent) {
+	return MessageTypeFacebookQuickReply, &MessageContent{FacebookQuickReply: message}
+}
+
+// FacebookMediaTemplateMessage creates Facebook Messenger media template
+// content.
+func FacebookMediaTemplateMessage(message *FacebookMessage) (MessageType, *MessageContent) {
+	return MessageTypeFacebookMediaTemplate, &MessageContent{FacebookMediaTemplate: message}
+}
+
+// FacebookGenericTemplateMessage creates Facebook Messenger generic template
+// content.
+func FacebookGenericTemplateMessage(message *FacebookMessage) (MessageType, *MessageContent) {
+	return MessageTypeFacebookGenericTemplate, &MessageContent{FacebookGenericTemplate: message}
+}
+
+// FacebookButtonTemplateMessage creates Facebook Messenger button template
+// content: text with up to three buttons.
+func FacebookButtonTemplateMessage(text string, buttons ...*FacebookButton) (MessageType, *MessageContent) {
+	return MessageTypeFacebookButtonTemplate, &MessageContent{FacebookButtonTemplate: &FacebookMessage{
+		Attachment: &FacebookAttachment{
+			Type: FBAttachmentTypeTemplate,
+			Payload: &FacebookAttachmentPayload{
+				TemplateType: FBTemplateTypeButton,
+				Text:         text,
+				Buttons:      buttons,
+			},
+		},
+	}}
+}
+
+// FacebookReceiptTemplateMessage creates Facebook Messenger receipt template
+// content. The template type of receipt is set to FBTemplateTypeReceipt.
+func FacebookReceiptTemplateMessage(receipt *FacebookAttachmentPayload) (MessageType, *MessageContent) {
+	receipt.TemplateType = FBTemplateTypeReceipt
+	return MessageTypeFacebookReceiptTemplate, &MessageContent{FacebookReceiptTemplate: &FacebookMessage{
+		Attachment: &FacebookAttachment{
+			Type:    FBAttachmentTypeTemplate,
+			Payload: receipt,
+		},
+	}}
+}
+
+// SetContent sets the type and content of the request.
+func (r *SendMessageRequest) SetContent(messageType MessageType, content *MessageContent) {
+	r.Type, r.Content = messageType, content
+}
+
+// SetContent sets the type and content of the request.
+func (r *ReplyRequest) SetContent(messageType MessageType, content *MessageContent) {
+	r.Type, r.Content = messageType, content
+}
+
+// SetContent sets the type and content of the request.
+func (r *StartRequest) SetContent(messageType MessageType, content *MessageContent) {
+	r.Type, r.Content = messageType, content
+}
+
+// Type gets the MessageType that matches the field that is set. It returns an
+// error if no field or more than one field is set.
+func (c *MessageContent) Type() (MessageType, error) {
+	if c == nil {
+		return "", errors.New("content is required")
+	}
+
+	var types []MessageType
+	set := func(ok bool, messageType MessageType) {
+		if ok {
+			types = append(types, messageType)
+		}
+	}
+
+	set(c.Text != "", MessageTypeText)
+	set(c.Image != nil, MessageTypeImage)
+	set(c.Video != nil, MessageTypeVideo)
+	set(c.Audio != nil, MessageTypeAudio)
+	set(c.File != nil, MessageTypeFile)
+	set(c.Location != nil, MessageTypeLocation)
+	set(c.HSM != nil, MessageTypeHSM)
+	set(c.Interactive != nil, MessageTypeInteractive)
+	set(c.WhatsAppSticker != nil, MessageTypeWhatsAppSticker)
+	set(c.WhatsAppOrder != nil, MessageTypeWhatsappOrder)
+	set(c.WhatsAppText != nil, MessageTypeWhatsappText)
+	set(c.FacebookQuickReply != nil, MessageTypeFacebookQuickReply)
+	set(c.FacebookMediaTemplate != nil, MessageTypeFacebookMediaTemplate)
+	set(c.FacebookGenericTemplate != nil, MessageTypeFacebookGenericTemplate)
+	set(c.FacebookButtonTemplate != nil, MessageTypeFacebookButtonTemplate)
+	set(c.FacebookReceiptTemplate != nil, MessageTypeFacebookReceiptTemplate)
+	set(c.FacebookPostback != nil, MessageTypeFacebookPostback)
+	set(c.Email != nil, MessageTypeEmail)
+	set(len(c.ExternalAttachments) > 0, MessageTypeExternalAttachment)
+
+	switch len(types) {
+	case 0:
+		return "", errors.New("content is empty")
+	case 1:
+		return types[0], nil
+	default:
+		return "", fmt.Errorf("content can only have one type, but has %v", types)
+	}
+}
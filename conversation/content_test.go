@@ -0,0 +1,105 @@
+package conversation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageConstructors(t *testing.T) {
+	cases := []struct {
+		name string
+		new  func() (MessageType, *MessageContent)
+		want MessageType
+	}{
+		{"text", func() (MessageType, *MessageContent) { return TextMessage("Hello") }, MessageTypeText},
+		{"image", func() (MessageType, *MessageContent) { return ImageMessage("https://example.com/cat.jpg", "A cat") }, MessageTypeImage},
+		{"video", func() (MessageType, *MessageContent) { return VideoMessage("https://example.com/cat.mp4", "") }, MessageTypeVideo},
+		{"audio", func() (MessageType, *MessageContent) { return AudioMessage("https://example.com/cat.mp3") }, MessageTypeAudio},
+		{"file", func() (MessageType, *MessageContent) { return FileMessage("https://example.com/cat.pdf", "") }, MessageTypeFile},
+		{"location", func() (MessageType, *MessageContent) { return LocationMessage(52.37, 4.89) }, MessageTypeLocation},
+		{"hsm", func() (MessageType, *MessageContent) { return HSMMessage(&HSM{TemplateName: "welcome"}) }, MessageTypeHSM},
+		{"interactive", func() (MessageType, *MessageContent) {
+			return InteractiveMessage(&WhatsAppInteractive{Type: WAITypeButton})
+		}, MessageTypeInteractive},
+		{"sticker", func() (MessageType, *MessageContent) { return WhatsAppStickerMessage("https://example.com/cat.webp") }, MessageTypeWhatsAppSticker},
+		{"email", func() (MessageType, *MessageContent) { return EmailMessage(&Email{Subject: "Hello"}) }, MessageTypeEmail},
+		{"external attachment", func() (MessageType, *MessageContent) {
+			return ExternalAttachmentMessage(&Media{URL: "https://example.com/cat.jpg"})
+		}, MessageTypeExternalAttachment},
+		{"facebook quick reply", func() (MessageType, *MessageContent) {
+			return FacebookQuickReplyMessage(&FacebookMessage{Text: "Hello"})
+		}, MessageTypeFacebookQuickReply},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			messageType, content := test.new()
+			assert.Equal(t, test.want, messageType)
+
+			contentType, err := content.Type()
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, contentType)
+		})
+	}
+}
+
+func TestSetContent(t *testing.T) {
+	req := &SendMessageRequest{To: "+31612345678", From: "chid"}
+	req.SetContent(ImageMessage("https://example.com/cat.jpg", "A cat"))
+
+	assert.Equal(t, MessageTypeImage, req.Type)
+	assert.Equal(t, "A cat", req.Content.Image.Caption)
+}
+
+func TestMessageContentType(t *testing.T) {
+	_, err := (&MessageContent{}).Type()
+	assert.EqualError(t, err, "content is empty")
+
+	_, err = (&MessageContent{Text: "Hello", Image: &Image{URL: "https://example.com/cat.jpg"}}).Type()
+	assert.EqualError(t, err, "content can only have one type, but has [text image]")
+}
+
+func TestValidateContent(t *testing.T) {
+	email := &Email{
+		To:      []*EmailRecipient{{Address: "to@example.com"}},
+		From:    &EmailRecipient{Address: "from@example.com"},
+		Subject: "Hello",
+		Content: &EmailContent{Text: "Hello"},
+	}
+
+	cases := []struct {
+		name     string
+		platform Platform
+		req      *SendMessageRequest
+		wantErr  string
+	}{
+		{"text", PlatformWhatsApp, &SendMessageRequest{Type: MessageTypeText, Content: &MessageContent{Text: "Hello"}}, ""},
+		{"type mismatch", "", &SendMessageRequest{Type: MessageTypeImage, Content: &MessageContent{Text: "Hello"}}, "type is image, but content is text"},
+		{"no content", PlatformSMS, &SendMessageRequest{Type: MessageTypeText}, "content is required"},
+		{"unknown platform", "viber", &SendMessageRequest{Type: MessageTypeText, Content: &MessageContent{Text: strings.Repeat("a", 5000)}}, ""},
+		{"whatsapp text too long", PlatformWhatsApp, &SendMessageRequest{Type: MessageTypeText, Content: &MessageContent{Text: strings.Repeat("a", 4097)}}, "whatsapp: text exceeds 4096 characters"},
+		{"whatsapp caption too long", PlatformWhatsApp, &SendMessageRequest{Type: MessageTypeImage, Content: &MessageContent{Image: &Image{URL: "https://example.com/cat.jpg", Caption: strings.Repeat("a", 1025)}}}, "whatsapp: caption exceeds 1024 characters"},
+		{"whatsapp media without url", PlatformWhatsApp, &SendMessageRequest{Type: MessageTypeVideo, Content: &MessageContent{Video: &Video{}}}, "whatsapp: media url is required"},
+		{"facebook text", PlatformFacebook, &SendMessageRequest{Type: MessageTypeText, Content: &MessageContent{Text: strings.Repeat("ä", 2000)}}, ""},
+		{"facebook quick replies", PlatformFacebook, &SendMessageRequest{Type: MessageTypeFacebookQuickReply, Content: &MessageContent{FacebookQuickReply: &FacebookMessage{Text: "Pick one", QuickReplies: make([]*FacebookQuickReply, 14)}}}, "facebook: at most 13 quick replies are allowed"},
+		{"facebook hsm", PlatformFacebook, &SendMessageRequest{Type: MessageTypeHSM, Content: &MessageContent{HSM: &HSM{}}}, "facebook: type hsm is not supported"},
+		{"sms text too long", PlatformSMS, &SendMessageRequest{Type: MessageTypeText, Content: &MessageContent{Text: strings.Repeat("a", 1378)}}, "sms: text exceeds 1377 characters"},
+		{"sms image", PlatformSMS, &SendMessageRequest{Type: MessageTypeImage, Content: &MessageContent{Image: &Image{URL: "https://example.com/cat.jpg"}}}, "sms: type image is not supported"},
+		{"email", PlatformEmail, &SendMessageRequest{Type: MessageTypeEmail, Content: &MessageContent{Email: email}}, ""},
+		{"email without subject", PlatformEmail, &SendMessageRequest{Type: MessageTypeEmail, Content: &MessageContent{Email: &Email{To: email.To, From: email.From, Content: email.Content}}}, "email: email subject is required"},
+		{"email text", PlatformEmail, &SendMessageRequest{Type: MessageTypeText, Content: &MessageContent{Text: "Hello"}}, "email: type text is not supported"},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.req.Validate(test.platform)
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.wantErr)
+			}
+		})
+	}
+}
@@ -31,19 +31,10 @@ type Conversation struct {
 	UpdatedDatetime      *time.Time
 	LastReceivedDatetime *time.Time
 	LastUsedChannelID    string
-	lastUsedPlatformId   Platform
+	LastUsedPlatformID   Platform
 	Messages             *MessagesCount
 }
 
-type Channel struct {
-	ID              string
-	Name            string
-	PlatformID      string
-	Status          string
-	CreatedDatetime *time.Time
-	UpdatedDatetime *time.Time
-}
-
 type MessagesCount struct {
 	HRef          string
 	TotalCount    int
@@ -53,8 +44,6 @@ type MessagesCount struct {
 // Status indicates what state a Conversation is in.
 type Status string
 
-type Platform string
-
 type Conversations struct {
 	Offset     int
 	Limit      int
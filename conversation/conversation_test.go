@@ -108,6 +108,8 @@ func TestRead(t *testing.T) {
 	assert.Equal(t, "chname", conv.Channels[0].Name)
 	assert.Equal(t, 1, conv.Messages.TotalCount)
 	assert.Equal(t, ConversationStatusActive, conv.Status)
+	assert.Equal(t, PlatformTelegram, conv.LastUsedPlatformID)
+	assert.Equal(t, conv.Channels[0], conv.LastUsedChannel())
 
 	mbtest.AssertEndpointCalled(t, http.MethodGet, "/v1/conversations/convid")
 }
@@ -0,0 +1,397 @@
+package conversation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// Substitutions are replaced with the EmailRecipientVariables of each
+// recipient when PerformSubstitutions is set.
+const (
+	EmailSubstitutionFirstName = "{{firstname}}"
+	EmailSubstitutionLastName  = "{{lastname}}"
+)
+
+// EmailHeaders are custom headers of an email, keyed by their canonical name,
+// e.g. "X-Campaign".
+type EmailHeaders map[string]string
+
+// reservedEmailHeaders can't be set as custom headers, because they are set
+// from the fields of the Email.
+var reservedEmailHeaders = map[string]bool{
+	"From":         true,
+	"To":           true,
+	"Cc":           true,
+	"Bcc":          true,
+	"Subject":      true,
+	"Reply-To":     true,
+	"Return-Path":  true,
+	"Content-Type": true,
+}
+
+// EmailComposer creates an Email. Attachments and inline images are uploaded
+// with UploadMedia when they are added, because the API only accepts them as
+// URLs. Errors are returned by Build:
+//
+//	email, err := conversation.NewEmailComposer(client).
+//		From("support@example.com", "Support").
+//		To("jen@example.com", "Jen", &conversation.EmailRecipientVariables{FirstName: "Jen"}).
+//		Subject("Your invoice").
+//		HTML(`<p>Hi {{firstname}},</p><img src="cid:logo">`).
+//		InlineImageFile("logo", "logo.png").
+//		AttachFile("invoice.pdf").
+//		Build()
+type EmailComposer struct {
+	client  *messagebird.DefaultClient
+	email   *Email
+	headers EmailHeaders
+	err     error
+}
+
+// NewEmailComposer creates a composer that uploads files with client.
+// Substitutions are performed by default.
+func NewEmailComposer(client *messagebird.DefaultClient) *EmailComposer {
+	return &EmailComposer{
+		client: client,
+		email: &Email{
+			Content:              &EmailContent{},
+			PerformSubstitutions: true,
+		},
+		headers: make(EmailHeaders),
+	}
+}
+
+// From sets the sender.
+func (c *EmailComposer) From(address, name string) *EmailComposer {
+	c.email.From = &EmailRecipient{Address: address, Name: name}
+	return c
+}
+
+// To adds a recipient. The variables are optional.
+func (c *EmailComposer) To(address, name string, variables *EmailRecipientVariables) *EmailComposer {
+	c.email.To = append(c.email.To, &EmailRecipient{Address: address, Name: name, Variables: variables})
+	return c
+}
+
+// ReplyTo sets the address replies are sent to.
+func (c *EmailComposer) ReplyTo(address string) *EmailComposer {
+	c.email.ReplyTo = address
+	return c
+}
+
+// Subject sets the subject.
+func (c *EmailComposer) Subject(subject string) *EmailComposer {
+	c.email.Subject = subject
+	return c
+}
+
+// HTML sets the HTML content. Inline images are referenced as "cid:<id>".
+func (c *EmailComposer) HTML(html string) *EmailComposer {
+	c.email.Content.Html = html
+	return c
+}
+
+// Text sets the plain text content.
+func (c *EmailComposer) Text(text string) *EmailComposer {
+	c.email.Content.Text = text
+	return c
+}
+
+// Header sets a custom header. Headers that are set from other fields, like
+// Subject, can't be set.
+func (c *EmailComposer) Header(name, value string) *EmailComposer {
+	key := textproto.CanonicalMIMEHeaderKey(name)
+	switch {
+	case !validHeaderName(name):
+		c.setErr(fmt.Errorf("invalid header name %q", name))
+	case reservedEmailHeaders[key]:
+		c.setErr(fmt.Errorf("header %s can't be set as a custom header", key))
+	case strings.ContainsAny(value, "\r\n"):
+		c.setErr(fmt.Errorf("header %s contains a line break", key))
+	default:
+		c.headers[key] = value
+	}
+	return c
+}
+
+// Tracking sets whether opens and clicks are tracked.
+func (c *EmailComposer) Tracking(open, click bool) *EmailComposer {
+	c.email.Tracking = &EmailTracking{Open: open, Click: click}
+	return c
+}
+
+// PerformSubstitutions sets whether EmailRecipientVariables are substituted.
+func (c *EmailComposer) PerformSubstitutions(substitute bool) *EmailComposer {
+	c.email.PerformSubstitutions = substitute
+	return c
+}
+
+// Attach uploads r and attaches it as name. The content type is detected from
+// the extension of name, or else from the content. Nothing is uploaded once an
+// error occurred.
+func (c *EmailComposer) Attach(name string, r io.Reader) *EmailComposer {
+	if c.err != nil {
+		return c
+	}
+
+	media, err := c.upload(name, r)
+	if err != nil {
+		c.setErr(fmt.Errorf("attachment %s: %v", name, err))
+		return c
+	}
+
+	c.email.Attachments = append(c.email.Attachments, &EmailAttachment{
+		Id:     media.ID,
+		Name:   name,
+		Type:   media.contentType,
+		URL:    media.URL,
+		Length: media.length,
+	})
+	return c
+}
+
+// AttachFile uploads the file at path and attaches it with its base name.
+func (c *EmailComposer) AttachFile(path string) *EmailComposer {
+	if c.err != nil {
+		return c
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		c.setErr(err)
+		return c
+	}
+	defer f.Close()
+
+	return c.Attach(filepath.Base(path), f)
+}
+
+// InlineImage uploads r as an image that the HTML content references as
+// "cid:<contentID>". Nothing is uploaded once an error occurred.
+func (c *EmailComposer) InlineImage(contentID, name string, r io.Reader) *EmailComposer {
+	if c.err != nil {
+		return c
+	}
+
+	media, err := c.upload(name, r)
+	if err != nil {
+		c.setErr(fmt.Errorf("inline image %s: %v", name, err))
+		return c
+	}
+	if !strings.HasPrefix(media.contentType, "image/") {
+		c.setErr(fmt.Errorf("inline image %s: content type is %s, not an image", name, media.contentType))
+		return c
+	}
+
+	c.email.InlineImages = append(c.email.InlineImages, &EmailInlineImage{
+		Id:        media.ID,
+		Name:      name,
+		Type:      media.contentType,
+		URL:       media.URL,
+		Length:    media.length,
+		ContentId: contentID,
+	})
+	return c
+}
+
+// InlineImageFile uploads the image at path as an inline image.
+func (c *EmailComposer) InlineImageFile(contentID, path string) *EmailComposer {
+	if c.err != nil {
+		return c
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		c.setErr(err)
+		return c
+	}
+	defer f.Close()
+
+	return c.InlineImage(contentID, filepath.Base(path), f)
+}
+
+// Build validates the email and returns a copy of it, so the composer can
+// be reused for a similar email. It returns the first error that occurred
+// while composing.
+func (c *EmailComposer) Build() (*Email, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	email := c.email.copy()
+	if err := validateEmail(email); err != nil {
+		return nil, err
+	}
+
+	if err := validateEmailAddress(email.From.Address); err != nil {
+		return nil, err
+	}
+	for _, to := range email.To {
+		if err := validateEmailAddress(to.Address); err != nil {
+			return nil, err
+		}
+	}
+	if email.ReplyTo != "" {
+		if err := validateEmailAddress(email.ReplyTo); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, image := range email.InlineImages {
+		if !strings.Contains(email.Content.Html, "cid:"+image.ContentId) {
+			return nil, fmt.Errorf("inline image %s is not referenced as cid:%s", image.Name, image.ContentId)
+		}
+	}
+
+	if len(c.headers) > 0 {
+		headers := make(EmailHeaders, len(c.headers))
+		for name, value := range c.headers {
+			headers[name] = value
+		}
+		email.Headers = headers
+	}
+
+	return email, nil
+}
+
+// copy copies the email, so that changing the content, recipients or files of
+// one does not change the other.
+func (e *Email) copy() *Email {
+	email := *e
+	if e.From != nil {
+		from := *e.From
+		email.From = &from
+	}
+	if e.Content != nil {
+		content := *e.Content
+		email.Content = &content
+	}
+	if e.Tracking != nil {
+		tracking := *e.Tracking
+		email.Tracking = &tracking
+	}
+
+	email.To = nil
+	for _, to := range e.To {
+		recipient := *to
+		email.To = append(email.To, &recipient)
+	}
+	email.Attachments = nil
+	for _, attachment := range e.Attachments {
+		a := *attachment
+		email.Attachments = append(email.Attachments, &a)
+	}
+	email.InlineImages = nil
+	for _, image := range e.InlineImages {
+		img := *image
+		email.InlineImages = append(email.InlineImages, &img)
+	}
+
+	return &email
+}
+
+// Preview renders the email as it would be sent to recipient, by performing
+// the substitutions in the subject and content. The returned Email only has
+// recipient in To. The email itself is not changed.
+func (e *Email) Preview(recipient *EmailRecipient) *Email {
+	preview := *e
+	preview.To = []*EmailRecipient{recipient}
+	if !e.PerformSubstitutions {
+		return &preview
+	}
+
+	var variables EmailRecipientVariables
+	if recipient.Variables != nil {
+		variables = *recipient.Variables
+	}
+	r := strings.NewReplacer(
+		EmailSubstitutionFirstName, variables.FirstName,
+		EmailSubstitutionLastName, variables.LastName,
+	)
+
+	preview.Subject = r.Replace(e.Subject)
+	if e.Content != nil {
+		preview.Content = &EmailContent{
+			Html: r.Replace(e.Content.Html),
+			Text: r.Replace(e.Content.Text),
+		}
+	}
+
+	return &preview
+}
+
+func (c *EmailComposer) setErr(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// uploadedFile is an UploadedMedia with the details an email needs.
+type uploadedFile struct {
+	*UploadedMedia
+	contentType string
+	length      int
+}
+
+func (c *EmailComposer) upload(name string, r io.Reader) (*uploadedFile, error) {
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		br := bufio.NewReaderSize(r, sniffLen)
+		head, _ := br.Peek(sniffLen)
+		contentType = http.DetectContentType(head)
+		r = br
+	}
+
+	counter := &countingReader{r: r}
+	media, err := UploadMedia(c.client, counter, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &uploadedFile{UploadedMedia: media, contentType: contentType, length: counter.n}, nil
+}
+
+// countingReader counts the bytes that are read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func validateEmailAddress(address string) error {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return fmt.Errorf("invalid email address %q", address)
+	}
+
+	return nil
+}
+
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		// Header names are printable ASCII, except for the colon.
+		if r <= ' ' || r > '~' || r == ':' {
+			return false
+		}
+	}
+
+	return true
+}
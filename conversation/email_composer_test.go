@@ -0,0 +1,151 @@
+package conversation
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// emailComposerTestClient creates a composer for the test server, which
+// responds to uploads with file1 and then file2.
+func emailComposerTestClient(t *testing.T) *EmailComposer {
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v1/files", "mediaUploadObject.json", http.StatusCreated)
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v1/files", "mediaUploadSecondObject.json", http.StatusCreated)
+	mbtest.ResetRequests()
+
+	return NewEmailComposer(mbtest.Client(t))
+}
+
+func TestEmailComposer(t *testing.T) {
+	composer := emailComposerTestClient(t)
+
+	logo := filepath.Join(t.TempDir(), "logo")
+	assert.NoError(t, ioutil.WriteFile(logo, pngHeader, 0600))
+
+	email, err := composer.
+		From("support@example.com", "Support").
+		To("jen@example.com", "Jen", &EmailRecipientVariables{FirstName: "Jen"}).
+		Subject("Your invoice, {{firstname}}").
+		HTML(`<p>Hi {{firstname}},</p><img src="cid:logo">`).
+		Header("x-campaign", "invoices").
+		InlineImageFile("logo", logo).
+		Attach("invoice.pdf", strings.NewReader("%PDF-1.4")).
+		Build()
+	assert.NoError(t, err)
+
+	assert.Equal(t, EmailHeaders{"X-Campaign": "invoices"}, email.Headers)
+	assert.Equal(t, &EmailInlineImage{
+		Id:        "file1",
+		Name:      "logo",
+		Type:      "image/png",
+		URL:       "https://messaging.messagebird.com/v1/files/file1",
+		Length:    len(pngHeader),
+		ContentId: "logo",
+	}, email.InlineImages[0])
+	assert.Equal(t, &EmailAttachment{
+		Id:     "file2",
+		Name:   "invoice.pdf",
+		Type:   "application/pdf",
+		URL:    "https://messaging.messagebird.com/v1/files/file2",
+		Length: 8,
+	}, email.Attachments[0])
+
+	preview := email.Preview(email.To[0])
+	assert.Equal(t, "Your invoice, Jen", preview.Subject)
+	assert.Equal(t, `<p>Hi Jen,</p><img src="cid:logo">`, preview.Content.Html)
+	assert.Equal(t, "Your invoice, {{firstname}}", email.Subject)
+
+	assert.Len(t, mbtest.Requests(), 2)
+	mbtest.AssertEndpointCalled(t, http.MethodPost, "/v1/files")
+	assert.Equal(t, "application/pdf", mbtest.Request.ContentType)
+	assert.Equal(t, "%PDF-1.4", string(mbtest.Request.Body))
+
+	// The composer can be reused without changing the email it built.
+	second, err := composer.To("tim@example.com", "Tim", nil).Header("X-Campaign", "reminders").Build()
+	assert.NoError(t, err)
+	assert.Len(t, second.To, 2)
+	assert.Len(t, email.To, 1)
+	assert.Equal(t, EmailHeaders{"X-Campaign": "invoices"}, email.Headers)
+
+	second.Content.Html = "<p>Changed</p>"
+	assert.Equal(t, `<p>Hi {{firstname}},</p><img src="cid:logo">`, email.Content.Html)
+}
+
+func TestEmailComposerErrors(t *testing.T) {
+	valid := func(c *EmailComposer) *EmailComposer {
+		return c.From("support@example.com", "").To("jen@example.com", "", nil).Subject("Hi").Text("Hello")
+	}
+
+	cases := []struct {
+		name    string
+		compose func(*EmailComposer) *EmailComposer
+		wantErr string
+	}{
+		{
+			"invalid to",
+			func(c *EmailComposer) *EmailComposer { return valid(c).To("Jen <jen@example.com>", "", nil) },
+			`invalid email address "Jen <jen@example.com>"`,
+		},
+		{
+			"invalid from",
+			func(c *EmailComposer) *EmailComposer { return valid(c).From("support", "") },
+			`invalid email address "support"`,
+		},
+		{
+			"reserved header",
+			func(c *EmailComposer) *EmailComposer { return valid(c).Header("subject", "Hi") },
+			"header Subject can't be set as a custom header",
+		},
+		{
+			"header injection",
+			func(c *EmailComposer) *EmailComposer { return valid(c).Header("X-Tag", "a\r\nBcc: x@example.com") },
+			"header X-Tag contains a line break",
+		},
+		{
+			"inline image that is not an image",
+			func(c *EmailComposer) *EmailComposer {
+				return valid(c).InlineImage("doc", "doc", bytes.NewReader([]byte("%PDF-1.4")))
+			},
+			"inline image doc: content type is application/pdf, not an image",
+		},
+		{
+			"unreferenced inline image",
+			func(c *EmailComposer) *EmailComposer {
+				return valid(c).HTML("<p>Hi</p>").InlineImage("logo", "logo.png", bytes.NewReader(pngHeader))
+			},
+			"inline image logo.png is not referenced as cid:logo",
+		},
+		{
+			"missing file",
+			func(c *EmailComposer) *EmailComposer { return valid(c).AttachFile("testdata/doesNotExist.pdf") },
+			"open testdata/doesNotExist.pdf: no such file or directory",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			composer := emailComposerTestClient(t)
+
+			_, err := test.compose(composer).Build()
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestEmailComposerNoUploadAfterError(t *testing.T) {
+	composer := emailComposerTestClient(t)
+
+	_, err := composer.
+		Header("Subject", "Hi").
+		Attach("invoice.pdf", strings.NewReader("%PDF-1.4")).
+		InlineImage("logo", "logo.png", bytes.NewReader(pngHeader)).
+		Build()
+	assert.EqualError(t, err, "header Subject can't be set as a custom header")
+	assert.Empty(t, mbtest.Requests())
+}
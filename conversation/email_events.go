@@ -0,0 +1,248 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EmailEventType is the kind of engagement with an email.
+type EmailEventType string
+
+const (
+	EmailEventOpened        EmailEventType = "opened"
+	EmailEventClicked       EmailEventType = "clicked"
+	EmailEventBounced       EmailEventType = "bounced"
+	EmailEventSpamComplaint EmailEventType = "spam_complaint"
+	EmailEventUnsubscribed  EmailEventType = "unsubscribed"
+)
+
+// emailEventTypes maps the statuses of email messages to their events.
+var emailEventTypes = map[MessageStatus]EmailEventType{
+	MessageStatusOpened:          EmailEventOpened,
+	MessageStatusClicked:         EmailEventClicked,
+	MessageStatusBounce:          EmailEventBounced,
+	MessageStatusSpamComplaint:   EmailEventSpamComplaint,
+	MessageStatusListUnsubscribe: EmailEventUnsubscribed,
+}
+
+// ErrNotEmailEvent is returned by DecodeEmailEvent for messages that are not
+// emails, or that have a status that is not an engagement event.
+var ErrNotEmailEvent = errors.New("message is not an email engagement event")
+
+// EmailEvent is an engagement event of an email that was sent, like an open
+// or a click. Opens and clicks are only reported when they are enabled with
+// EmailTracking.
+type EmailEvent struct {
+	Type EmailEventType
+
+	MessageID      string
+	ConversationID string
+	Recipient      string
+
+	// TrackID and Tag are those of the request the email was sent with.
+	TrackID string
+	Tag     MessageTag
+
+	// Datetime is when the event occurred.
+	Datetime *time.Time
+}
+
+// Campaign gets the campaign of the event: its TrackID, or its Tag if it has
+// no TrackID.
+func (e *EmailEvent) Campaign() string {
+	if e.TrackID != "" {
+		return e.TrackID
+	}
+
+	return string(e.Tag)
+}
+
+// DecodeEmailEvent gets the engagement event from the message of a
+// message.updated event. It returns ErrNotEmailEvent for other messages.
+func DecodeEmailEvent(message *Message) (*EmailEvent, error) {
+	if message == nil || Platform(message.Platform) != PlatformEmail {
+		return nil, ErrNotEmailEvent
+	}
+
+	eventType, ok := emailEventTypes[message.Status]
+	if !ok {
+		return nil, ErrNotEmailEvent
+	}
+
+	return &EmailEvent{
+		Type:           eventType,
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		Recipient:      string(message.To),
+		TrackID:        message.TrackId,
+		Tag:            message.Tag,
+		Datetime:       message.UpdatedDatetime,
+	}, nil
+}
+
+// EmailCampaignStats holds the engagement of the emails of a campaign. Each
+// count is the number of emails that had the event at least once, so two
+// opens of the same email count once.
+type EmailCampaignStats struct {
+	Campaign string
+
+	// Sent is the number of emails that were seen for the campaign.
+	Sent int
+
+	Opened         int
+	Clicked        int
+	Bounced        int
+	SpamComplaints int
+	Unsubscribed   int
+}
+
+// Delivered is the number of emails that did not bounce.
+func (s *EmailCampaignStats) Delivered() int {
+	return s.Sent - s.Bounced
+}
+
+// OpenRate is the fraction of delivered emails that were opened.
+func (s *EmailCampaignStats) OpenRate() float64 {
+	return rate(s.Opened, s.Delivered())
+}
+
+// ClickThroughRate is the fraction of delivered emails that had a click.
+func (s *EmailCampaignStats) ClickThroughRate() float64 {
+	return rate(s.Clicked, s.Delivered())
+}
+
+// BounceRate is the fraction of sent emails that bounced.
+func (s *EmailCampaignStats) BounceRate() float64 {
+	return rate(s.Bounced, s.Sent)
+}
+
+func rate(n, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return float64(n) / float64(total)
+}
+
+// EmailCampaignAggregator aggregates the engagement of emails per campaign,
+// as returned by EmailEvent.Campaign. It is safe for concurrent use, so
+// HandleMessageUpdated can be passed to WebhookHandler.OnMessageUpdated.
+type EmailCampaignAggregator struct {
+	mu        sync.Mutex
+	campaigns map[string]*emailCampaign
+}
+
+// emailCampaign keeps the IDs of the messages per event, so events that are
+// reported more than once are counted once.
+type emailCampaign struct {
+	sent   map[string]bool
+	events map[EmailEventType]map[string]bool
+}
+
+// NewEmailCampaignAggregator creates an empty EmailCampaignAggregator.
+func NewEmailCampaignAggregator() *EmailCampaignAggregator {
+	return &EmailCampaignAggregator{
+		campaigns: make(map[string]*emailCampaign),
+	}
+}
+
+// AddMessage adds an email that was sent, with any status. Its engagement
+// event is added as well, if it has one. Messages on other platforms are
+// ignored.
+func (a *EmailCampaignAggregator) AddMessage(message *Message) {
+	if message == nil || Platform(message.Platform) != PlatformEmail || message.Direction == MessageDirectionReceived {
+		return
+	}
+
+	campaign := message.TrackId
+	if campaign == "" {
+		campaign = string(message.Tag)
+	}
+
+	a.mu.Lock()
+	a.campaign(campaign).sent[message.ID] = true
+	a.mu.Unlock()
+
+	if event, err := DecodeEmailEvent(message); err == nil {
+		a.Add(event)
+	}
+}
+
+// Add adds an engagement event. The email it is for counts as sent.
+func (a *EmailCampaignAggregator) Add(event *EmailEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	c := a.campaign(event.Campaign())
+	c.sent[event.MessageID] = true
+	if c.events[event.Type] == nil {
+		c.events[event.Type] = make(map[string]bool)
+	}
+	c.events[event.Type][event.MessageID] = true
+}
+
+// HandleMessageUpdated adds the message of a message.updated event. It can be
+// passed to WebhookHandler.OnMessageUpdated.
+func (a *EmailCampaignAggregator) HandleMessageUpdated(ctx context.Context, event *MessageEvent) error {
+	a.AddMessage(event.Message)
+	return nil
+}
+
+// Campaign gets the stats of a campaign. It returns nil if no emails were
+// added for it.
+func (a *EmailCampaignAggregator) Campaign(name string) *EmailCampaignStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	c, ok := a.campaigns[name]
+	if !ok {
+		return nil
+	}
+
+	return c.stats(name)
+}
+
+// Stats gets the stats of all campaigns, sorted by campaign.
+func (a *EmailCampaignAggregator) Stats() []*EmailCampaignStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := make([]*EmailCampaignStats, 0, len(a.campaigns))
+	for name, c := range a.campaigns {
+		stats = append(stats, c.stats(name))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Campaign < stats[j].Campaign
+	})
+
+	return stats
+}
+
+// campaign gets or creates the campaign with name. a.mu must be held.
+func (a *EmailCampaignAggregator) campaign(name string) *emailCampaign {
+	c, ok := a.campaigns[name]
+	if !ok {
+		c = &emailCampaign{
+			sent:   make(map[string]bool),
+			events: make(map[EmailEventType]map[string]bool),
+		}
+		a.campaigns[name] = c
+	}
+
+	return c
+}
+
+func (c *emailCampaign) stats(name string) *EmailCampaignStats {
+	return &EmailCampaignStats{
+		Campaign:       name,
+		Sent:           len(c.sent),
+		Opened:         len(c.events[EmailEventOpened]),
+		Clicked:        len(c.events[EmailEventClicked]),
+		Bounced:        len(c.events[EmailEventBounced]),
+		SpamComplaints: len(c.events[EmailEventSpamComplaint]),
+		Unsubscribed:   len(c.events[EmailEventUnsubscribed]),
+	}
+}
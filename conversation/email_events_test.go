@@ -0,0 +1,93 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func emailMessage(id string, status MessageStatus, trackID string) *Message {
+	return &Message{
+		ID:        id,
+		Platform:  string(PlatformEmail),
+		To:        "jen@example.com",
+		Direction: MessageDirectionSent,
+		Status:    status,
+		TrackId:   trackID,
+	}
+}
+
+func TestDecodeEmailEvent(t *testing.T) {
+	message := &Message{}
+	err := json.Unmarshal([]byte(`{
+		"id": "mesid",
+		"conversationId": "convid",
+		"platform": "email",
+		"to": "jen@example.com",
+		"direction": "sent",
+		"status": "clicked",
+		"tag": "newsletter",
+		"trackId": "spring-sale",
+		"updatedDatetime": "2021-03-01T12:00:00Z"
+	}`), message)
+	assert.NoError(t, err)
+
+	event, err := DecodeEmailEvent(message)
+	assert.NoError(t, err)
+	assert.Equal(t, EmailEventClicked, event.Type)
+	assert.Equal(t, "mesid", event.MessageID)
+	assert.Equal(t, "jen@example.com", event.Recipient)
+	assert.Equal(t, "spring-sale", event.Campaign())
+	assert.Equal(t, 12, event.Datetime.Hour())
+
+	event.TrackID = ""
+	assert.Equal(t, "newsletter", event.Campaign())
+
+	_, err = DecodeEmailEvent(emailMessage("mesid", MessageStatusDelivered, ""))
+	assert.Equal(t, ErrNotEmailEvent, err)
+
+	_, err = DecodeEmailEvent(&Message{Platform: string(PlatformSMS), Status: MessageStatusClicked})
+	assert.Equal(t, ErrNotEmailEvent, err)
+}
+
+func TestEmailCampaignAggregator(t *testing.T) {
+	a := NewEmailCampaignAggregator()
+
+	for _, message := range []*Message{
+		emailMessage("1", MessageStatusDelivered, "spring-sale"),
+		emailMessage("2", MessageStatusDelivered, "spring-sale"),
+		emailMessage("3", MessageStatusDelivered, "spring-sale"),
+		emailMessage("4", MessageStatusBounce, "spring-sale"),
+		emailMessage("1", MessageStatusOpened, "spring-sale"),
+		emailMessage("1", MessageStatusOpened, "spring-sale"),
+		emailMessage("1", MessageStatusClicked, "spring-sale"),
+		emailMessage("2", MessageStatusOpened, "spring-sale"),
+		emailMessage("5", MessageStatusListUnsubscribe, "newsletter"),
+		{ID: "6", Platform: string(PlatformSMS), Status: MessageStatusDelivered, TrackId: "spring-sale"},
+	} {
+		assert.NoError(t, a.HandleMessageUpdated(context.Background(), &MessageEvent{Message: message}))
+	}
+
+	stats := a.Campaign("spring-sale")
+	assert.Equal(t, &EmailCampaignStats{
+		Campaign: "spring-sale",
+		Sent:     4,
+		Opened:   2,
+		Clicked:  1,
+		Bounced:  1,
+	}, stats)
+	assert.Equal(t, 3, stats.Delivered())
+	assert.InDelta(t, 2.0/3, stats.OpenRate(), 0.0001)
+	assert.InDelta(t, 1.0/3, stats.ClickThroughRate(), 0.0001)
+	assert.InDelta(t, 0.25, stats.BounceRate(), 0.0001)
+
+	all := a.Stats()
+	assert.Len(t, all, 2)
+	assert.Equal(t, "newsletter", all[0].Campaign)
+	assert.Equal(t, 1, all[0].Unsubscribed)
+
+	assert.Nil(t, a.Campaign("unknown"))
+	assert.Equal(t, 0.0, (&EmailCampaignStats{}).OpenRate())
+}
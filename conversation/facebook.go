@@ -1,11 +1,21 @@
 package conversation
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
 // FacebookMessage
 // https://developers.messagebird.com/api/conversations/#facebookmessage-object
 type FacebookMessage struct {
 	Text         string                `json:"text,omitempty"`
 	Attachment   *FacebookAttachment   `json:"attachment,omitempty"`
 	QuickReplies []*FacebookQuickReply `json:"quick_replies,omitempty"`
+
+	// QuickReply is set on inbound messages when the customer tapped one of
+	// the QuickReplies.
+	QuickReply *FacebookQuickReplyResponse `json:"quick_reply,omitempty"`
 }
 
 type FacebookAttachmentType string
@@ -36,6 +46,22 @@ type FacebookAttachmentPayload struct {
 	TemplateType     FacebookTemplateType      `json:"template_type,omitempty"`
 	Elements         []*FacebookElement        `json:"elements,omitempty"`
 	ImageAspectRatio *FacebookImageAspectRatio `json:"image_aspect_ratio,omitempty"`
+
+	// Text and Buttons are used by the button template.
+	Text    string            `json:"text,omitempty"`
+	Buttons []*FacebookButton `json:"buttons,omitempty"`
+
+	// The fields below are used by the receipt template, together with
+	// Elements for the items that were ordered.
+	RecipientName string                       `json:"recipient_name,omitempty"`
+	OrderNumber   string                       `json:"order_number,omitempty"`
+	Currency      string                       `json:"currency,omitempty"`
+	PaymentMethod string                       `json:"payment_method,omitempty"`
+	OrderUrl      string                       `json:"order_url,omitempty"`
+	Timestamp     string                       `json:"timestamp,omitempty"`
+	Address       *FacebookReceiptAddress      `json:"address,omitempty"`
+	Summary       *FacebookReceiptSummary      `json:"summary,omitempty"`
+	Adjustments   []*FacebookReceiptAdjustment `json:"adjustments,omitempty"`
 }
 
 type FacebookTemplateType string
@@ -43,12 +69,14 @@ type FacebookTemplateType string
 const (
 	FBTemplateTypeMedia   FacebookTemplateType = "media"
 	FBTemplateTypeGeneric FacebookTemplateType = "generic"
+	FBTemplateTypeButton  FacebookTemplateType = "button"
+	FBTemplateTypeReceipt FacebookTemplateType = "receipt"
 )
 
 // FacebookElement
 // https://developers.messagebird.com/api/conversations/#facebookelement-object
 type FacebookElement struct {
-	MediaType     FacebookElementMediaType `json:"media_type"`
+	MediaType     FacebookElementMediaType `json:"media_type,omitempty"`
 	AttachmentId  string                   `json:"attachment_id,omitempty"`
 	MediaUrl      string                   `json:"media_url,omitempty"`
 	Buttons       []*FacebookButton        `json:"buttons,omitempty"`
@@ -56,6 +84,12 @@ type FacebookElement struct {
 	Subtitle      string                   `json:"subtitle,omitempty"`
 	DefaultAction *FacebookButton          `json:"default_action,omitempty"`
 	ImageUrl      string                   `json:"image_url,omitempty"`
+
+	// Quantity, Price and Currency are used by the elements of a receipt
+	// template.
+	Quantity int     `json:"quantity,omitempty"`
+	Price    float64 `json:"price,omitempty"`
+	Currency string  `json:"currency,omitempty"`
 }
 
 type FacebookElementMediaType string
@@ -77,9 +111,51 @@ const (
 // https://developers.messagebird.com/api/conversations/#facebookbutton-object
 type FacebookButton struct {
 	Type    FacebookButtonType `json:"type"`
-	Url     string             `json:"url"`
+	Url     string             `json:"url,omitempty"`
 	Title   string             `json:"title"`
-	Payload string             `json:"payload"`
+	Payload string             `json:"payload,omitempty"`
+}
+
+// FacebookURLButton creates a button that opens url.
+func FacebookURLButton(title, url string) *FacebookButton {
+	return &FacebookButton{Type: FBButtonTypeWebUrl, Title: title, Url: url}
+}
+
+// FacebookPostbackButton creates a button that sends payload back as a
+// postback when it is tapped.
+func FacebookPostbackButton(title, payload string) *FacebookButton {
+	return &FacebookButton{Type: FBButtonTypePostback, Title: title, Payload: payload}
+}
+
+// FacebookCallButton creates a button that calls phoneNumber, which must be
+// in international format, e.g. +31612345678.
+func FacebookCallButton(title, phoneNumber string) *FacebookButton {
+	return &FacebookButton{Type: FBButtonTypePhoneNumber, Title: title, Payload: phoneNumber}
+}
+
+// FacebookReceiptAddress is the shipping address of a receipt template.
+type FacebookReceiptAddress struct {
+	Street1    string `json:"street_1"`
+	Street2    string `json:"street_2,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	State      string `json:"state"`
+	Country    string `json:"country"`
+}
+
+// FacebookReceiptSummary holds the totals of a receipt template. Only
+// TotalCost is required.
+type FacebookReceiptSummary struct {
+	Subtotal     float64 `json:"subtotal,omitempty"`
+	ShippingCost float64 `json:"shipping_cost,omitempty"`
+	TotalTax     float64 `json:"total_tax,omitempty"`
+	TotalCost    float64 `json:"total_cost"`
+}
+
+// FacebookReceiptAdjustment is a discount on a receipt template.
+type FacebookReceiptAdjustment struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
 }
 
 // FacebookImageAspectRatio
@@ -100,6 +176,18 @@ type FacebookQuickReply struct {
 	ImageUrl    string                        `json:"image_url"`
 }
 
+// FacebookQuickReplyResponse holds the payload of the quick reply a customer
+// tapped.
+type FacebookQuickReplyResponse struct {
+	Payload string `json:"payload"`
+}
+
+// FacebookPostback is received when a customer taps a postback button.
+type FacebookPostback struct {
+	Title   string `json:"title"`
+	Payload string `json:"payload"`
+}
+
 // FacebookQuickReplyContentType
 // https://developers.messagebird.com/api/conversations/#facebookquickreplycontenttype-object
 type FacebookQuickReplyContentType string
@@ -109,3 +197,102 @@ const (
 	FBQuickReplyContentTypeUserPhoneNumber FacebookQuickReplyContentType = "user_phone_number"
 	FBQuickReplyContentTypeUserEmail       FacebookQuickReplyContentType = "user_email"
 )
+
+// Messenger message tags allow sending specific kinds of updates outside the
+// 24-hour window. Set them as the Tag of a request.
+// https://developers.facebook.com/docs/messenger-platform/send-messages/message-tags
+const (
+	MessageTagConfirmedEventUpdate MessageTag = "confirmed_event_update"
+	MessageTagPostPurchaseUpdate   MessageTag = "post_purchase_update"
+	MessageTagAccountUpdate        MessageTag = "account_update"
+
+	// MessageTagHumanAgent allows a human agent to respond within
+	// FacebookHumanAgentWindow instead of FacebookMessagingWindow.
+	MessageTagHumanAgent MessageTag = "human_agent"
+)
+
+const (
+	// FacebookMessagingWindow is how long after the customer's last message
+	// any message can be sent.
+	FacebookMessagingWindow = 24 * time.Hour
+
+	// FacebookHumanAgentWindow is how long after the customer's last message
+	// a message tagged MessageTagHumanAgent can be sent.
+	FacebookHumanAgentWindow = 7 * 24 * time.Hour
+)
+
+// ErrOutsideMessagingWindow is returned by CheckFacebookMessagingWindow when
+// Messenger would reject the message.
+var ErrOutsideMessagingWindow = errors.New("outside the messaging window, a message tag is required")
+
+// CheckFacebookMessagingWindow checks that a message with tag can be sent at
+// the given time, when the customer last sent a message at lastReceived, e.g.
+// the LastReceivedDatetime of a Conversation. A nil lastReceived means the
+// customer has not sent a message yet. The tag is empty for untagged
+// messages.
+func CheckFacebookMessagingWindow(lastReceived *time.Time, tag MessageTag, at time.Time) error {
+	window := FacebookMessagingWindow
+
+	switch tag {
+	case "":
+	case MessageTagConfirmedEventUpdate, MessageTagPostPurchaseUpdate, MessageTagAccountUpdate:
+		return nil
+	case MessageTagHumanAgent:
+		window = FacebookHumanAgentWindow
+	default:
+		return fmt.Errorf("unknown message tag %q", tag)
+	}
+
+	if lastReceived == nil || at.Sub(*lastReceived) > window {
+		return ErrOutsideMessagingWindow
+	}
+
+	return nil
+}
+
+// ErrNotFacebookReply is returned by DecodeFacebookReply for messages that are
+// not a quick reply or postback.
+var ErrNotFacebookReply = errors.New("message is not a quick reply or postback")
+
+// FacebookReplyEvent is a quick reply or postback button a customer tapped.
+type FacebookReplyEvent struct {
+	// Type is MessageTypeFacebookQuickReply or MessageTypeFacebookPostback.
+	Type MessageType
+
+	Title   string
+	Payload string
+
+	MessageID      string
+	ConversationID string
+	From           string
+}
+
+// DecodeFacebookReply gets the payload of an inbound quick reply or postback,
+// e.g. from a MessageEvent. It returns ErrNotFacebookReply for other messages.
+func DecodeFacebookReply(message *Message) (*FacebookReplyEvent, error) {
+	if message == nil || message.Content == nil {
+		return nil, ErrNotFacebookReply
+	}
+
+	event := &FacebookReplyEvent{
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		From:           message.From,
+	}
+
+	content := message.Content
+	switch {
+	case message.Type == MessageTypeFacebookPostback && content.FacebookPostback != nil:
+		event.Type = MessageTypeFacebookPostback
+		event.Title = content.FacebookPostback.Title
+		event.Payload = content.FacebookPostback.Payload
+	case message.Type == MessageTypeFacebookQuickReply && content.FacebookQuickReply != nil && content.FacebookQuickReply.QuickReply != nil:
+		event.Type = MessageTypeFacebookQuickReply
+		event.Title = content.FacebookQuickReply.Text
+		event.Payload = content.FacebookQuickReply.QuickReply.Payload
+	default:
+		return nil, ErrNotFacebookReply
+	}
+
+	return event, nil
+}
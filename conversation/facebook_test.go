@@ -0,0 +1,158 @@
+package conversation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFacebookButtonTemplateMessage(t *testing.T) {
+	messageType, content := FacebookButtonTemplateMessage("What do you want to do next?",
+		FacebookURLButton("Track order", "https://example.com/orders/1234"),
+		FacebookPostbackButton("Talk to us", "TALK"),
+	)
+	assert.Equal(t, MessageTypeFacebookButtonTemplate, messageType)
+	assert.NoError(t, ValidateContent(PlatformFacebook, messageType, content))
+
+	data, err := json.Marshal(content)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"facebookButtonTemplate": {
+			"attachment": {
+				"type": "template",
+				"payload": {
+					"is_reusable": false,
+					"template_type": "button",
+					"text": "What do you want to do next?",
+					"buttons": [
+						{"type": "web_url", "url": "https://example.com/orders/1234", "title": "Track order"},
+						{"type": "postback", "title": "Talk to us", "payload": "TALK"}
+					]
+				}
+			}
+		}
+	}`, string(data))
+}
+
+func TestFacebookReceiptTemplateMessage(t *testing.T) {
+	receipt := &FacebookAttachmentPayload{
+		RecipientName: "Jen Smith",
+		OrderNumber:   "1234",
+		Currency:      "EUR",
+		PaymentMethod: "Visa 1234",
+		Elements: []*FacebookElement{
+			{Title: "Notebook", Quantity: 2, Price: 4.5, Currency: "EUR"},
+		},
+		Summary: &FacebookReceiptSummary{TotalCost: 9},
+	}
+
+	messageType, content := FacebookReceiptTemplateMessage(receipt)
+	assert.Equal(t, MessageTypeFacebookReceiptTemplate, messageType)
+	assert.Equal(t, FBTemplateTypeReceipt, content.FacebookReceiptTemplate.Attachment.Payload.TemplateType)
+	assert.NoError(t, ValidateContent(PlatformFacebook, messageType, content))
+
+	receipt.Summary = nil
+	assert.EqualError(t, ValidateContent(PlatformFacebook, messageType, content), "facebook: receipt summary is required")
+}
+
+func TestValidateFacebookButtonTemplate(t *testing.T) {
+	messageType, content := FacebookButtonTemplateMessage("Pick one")
+	assert.EqualError(t, ValidateContent(PlatformFacebook, messageType, content), "facebook: button template needs 1 to 3 buttons, has 0")
+
+	messageType, content = FacebookButtonTemplateMessage("Pick one", FacebookURLButton("Open", ""))
+	assert.EqualError(t, ValidateContent(PlatformFacebook, messageType, content), `facebook: button "Open" needs a url`)
+
+	messageType, content = FacebookButtonTemplateMessage("Pick one", FacebookCallButton("Call us", "+31612345678"))
+	assert.NoError(t, ValidateContent(PlatformFacebook, messageType, content))
+}
+
+func TestCheckFacebookMessagingWindow(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Hour)
+	lastWeek := now.Add(-6 * 24 * time.Hour)
+	lastMonth := now.Add(-30 * 24 * time.Hour)
+
+	cases := []struct {
+		name         string
+		lastReceived *time.Time
+		tag          MessageTag
+		wantErr      error
+	}{
+		{"within window", &recent, "", nil},
+		{"outside window", &lastWeek, "", ErrOutsideMessagingWindow},
+		{"never received", nil, "", ErrOutsideMessagingWindow},
+		{"human agent within week", &lastWeek, MessageTagHumanAgent, nil},
+		{"human agent outside week", &lastMonth, MessageTagHumanAgent, ErrOutsideMessagingWindow},
+		{"account update", &lastMonth, MessageTagAccountUpdate, nil},
+		{"confirmed event update", nil, MessageTagConfirmedEventUpdate, nil},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.wantErr, CheckFacebookMessagingWindow(test.lastReceived, test.tag, now))
+		})
+	}
+
+	assert.EqualError(t, CheckFacebookMessagingWindow(&recent, "promotion", now), `unknown message tag "promotion"`)
+}
+
+func TestReplyRequestValidateFor(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	lastWeek := now.Add(-6 * 24 * time.Hour)
+
+	facebook := &Conversation{LastUsedPlatformID: PlatformFacebook, LastReceivedDatetime: &lastWeek}
+	req := &ReplyRequest{Type: MessageTypeText, Content: &MessageContent{Text: "Your order has shipped"}}
+	assert.Equal(t, ErrOutsideMessagingWindow, req.ValidateFor(facebook, now))
+
+	req.Tag = MessageTagPostPurchaseUpdate
+	assert.NoError(t, req.ValidateFor(facebook, now))
+
+	// The window only applies to Messenger.
+	req.Tag = ""
+	assert.NoError(t, req.ValidateFor(&Conversation{LastUsedPlatformID: PlatformSMS, LastReceivedDatetime: &lastWeek}, now))
+
+	req.Content.Text = strings.Repeat("a", 2001)
+	assert.EqualError(t, req.ValidateFor(facebook, now), "facebook: text exceeds 2000 characters")
+}
+
+func TestDecodeFacebookReply(t *testing.T) {
+	message := &Message{}
+	err := json.Unmarshal([]byte(`{
+		"id": "mesid",
+		"conversationId": "convid",
+		"from": "fbuserid",
+		"type": "facebookQuickReply",
+		"content": {
+			"facebookQuickReply": {
+				"text": "Red",
+				"quick_reply": {"payload": "COLOR_RED"}
+			}
+		}
+	}`), message)
+	assert.NoError(t, err)
+
+	reply, err := DecodeFacebookReply(message)
+	assert.NoError(t, err)
+	assert.Equal(t, &FacebookReplyEvent{
+		Type:           MessageTypeFacebookQuickReply,
+		Title:          "Red",
+		Payload:        "COLOR_RED",
+		MessageID:      "mesid",
+		ConversationID: "convid",
+		From:           "fbuserid",
+	}, reply)
+
+	reply, err = DecodeFacebookReply(&Message{
+		Type:    MessageTypeFacebookPostback,
+		Content: &MessageContent{FacebookPostback: &FacebookPostback{Title: "Talk to us", Payload: "TALK"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, MessageTypeFacebookPostback, reply.Type)
+	assert.Equal(t, "TALK", reply.Payload)
+
+	_, err = DecodeFacebookReply(&Message{Type: MessageTypeText, Content: &MessageContent{Text: "Hi"}})
+	assert.Equal(t, ErrNotFacebookReply, err)
+}
@@ -12,6 +12,10 @@ type HSM struct {
 	TemplateName          string                     `json:"templateName"`
 	Language              *HSMLanguage               `json:"language"`
 	LocalizableParameters []*HSMLocalizableParameter `json:"params"`
+
+	// Components hold the parameters of templates with headers or buttons
+	// that have placeholders. See HSMBuilder.
+	Components []*HSMComponent `json:"components,omitempty"`
 }
 
 // HSMLanguage is used to set the message's locale.
@@ -84,3 +88,48 @@ func DateTimeLocalizableHSMParameter(d string, dateTime time.Time) *HSMLocalizab
 		DateTime: &dateTime,
 	}
 }
+
+// HSMComponentType is the part of a template an HSMComponent holds the
+// parameters for.
+type HSMComponentType string
+
+const (
+	HSMComponentTypeHeader HSMComponentType = "header"
+	HSMComponentTypeBody   HSMComponentType = "body"
+	HSMComponentTypeButton HSMComponentType = "button"
+)
+
+// HSMComponentParameterType is the kind of value of an HSMComponentParameter.
+type HSMComponentParameterType string
+
+const (
+	HSMComponentParameterTypeText     HSMComponentParameterType = "text"
+	HSMComponentParameterTypeCurrency HSMComponentParameterType = "currency"
+	HSMComponentParameterTypeDateTime HSMComponentParameterType = "date_time"
+	HSMComponentParameterTypeImage    HSMComponentParameterType = "image"
+	HSMComponentParameterTypeVideo    HSMComponentParameterType = "video"
+	HSMComponentParameterTypeDocument HSMComponentParameterType = "document"
+	HSMComponentParameterTypePayload  HSMComponentParameterType = "payload"
+)
+
+// HSMComponent holds the parameters of a part of a template. For buttons,
+// SubType is the button type in lower case (e.g. url) and Index its position.
+type HSMComponent struct {
+	Type       HSMComponentType         `json:"type"`
+	SubType    string                   `json:"sub_type,omitempty"`
+	Index      *int                     `json:"index,omitempty"`
+	Parameters []*HSMComponentParameter `json:"parameters"`
+}
+
+// HSMComponentParameter replaces a placeholder of a component. Only the
+// field matching Type is set.
+type HSMComponentParameter struct {
+	Type     HSMComponentParameterType        `json:"type"`
+	Text     string                           `json:"text,omitempty"`
+	Payload  string                           `json:"payload,omitempty"`
+	Currency *HSMLocalizableParameterCurrency `json:"currency,omitempty"`
+	DateTime *time.Time                       `json:"dateTime,omitempty"`
+	Image    *Media                           `json:"image,omitempty"`
+	Video    *Media                           `json:"video,omitempty"`
+	Document *Media                           `json:"document,omitempty"`
+}
@@ -0,0 +1,252 @@
+package conversation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HSMBuilder creates an HSM for a WhatsAppTemplate. It checks that the
+// parameters match the placeholders of the template, so mistakes are caught
+// before the message is sent instead of when WhatsApp rejects it:
+//
+//	hsm, err := conversation.NewHSMBuilder(template).
+//		HeaderImage("https://example.com/order.jpg").
+//		Body(conversation.DefaultLocalizableHSMParameter("Jen")).
+//		ButtonURL(0, "orders/1234").
+//		Build()
+type HSMBuilder struct {
+	template *WhatsAppTemplate
+	policy   HSMLanguagePolicy
+	header   *HSMComponentParameter
+	body     []*HSMLocalizableParameter
+	buttons  map[int]*HSMComponentParameter
+}
+
+// NewHSMBuilder creates a builder for template. The template's language is
+// used with HSMLanguagePolicyDeterministic.
+func NewHSMBuilder(template *WhatsAppTemplate) *HSMBuilder {
+	return &HSMBuilder{
+		template: template,
+		policy:   HSMLanguagePolicyDeterministic,
+		buttons:  make(map[int]*HSMComponentParameter),
+	}
+}
+
+// LanguagePolicy sets how the template's language is enforced.
+func (b *HSMBuilder) LanguagePolicy(policy HSMLanguagePolicy) *HSMBuilder {
+	b.policy = policy
+	return b
+}
+
+// HeaderText sets the parameter of a text header with a placeholder.
+func (b *HSMBuilder) HeaderText(text string) *HSMBuilder {
+	b.header = &HSMComponentParameter{Type: HSMComponentParameterTypeText, Text: text}
+	return b
+}
+
+// HeaderImage sets the image of an image header.
+func (b *HSMBuilder) HeaderImage(url string) *HSMBuilder {
+	b.header = &HSMComponentParameter{Type: HSMComponentParameterTypeImage, Image: &Media{URL: url}}
+	return b
+}
+
+// HeaderVideo sets the video of a video header.
+func (b *HSMBuilder) HeaderVideo(url string) *HSMBuilder {
+	b.header = &HSMComponentParameter{Type: HSMComponentParameterTypeVideo, Video: &Media{URL: url}}
+	return b
+}
+
+// HeaderDocument sets the document of a document header.
+func (b *HSMBuilder) HeaderDocument(url string) *HSMBuilder {
+	b.header = &HSMComponentParameter{Type: HSMComponentParameterTypeDocument, Document: &Media{URL: url}}
+	return b
+}
+
+// Body sets the parameters for the placeholders of the body, in order.
+func (b *HSMBuilder) Body(params ...*HSMLocalizableParameter) *HSMBuilder {
+	b.body = params
+	return b
+}
+
+// ButtonURL sets the suffix of the URL of the button at index, for URL
+// buttons with a placeholder.
+func (b *HSMBuilder) ButtonURL(index int, suffix string) *HSMBuilder {
+	b.buttons[index] = &HSMComponentParameter{Type: HSMComponentParameterTypeText, Text: suffix}
+	return b
+}
+
+// ButtonPayload sets the payload that is sent back when the quick reply
+// button at index is tapped.
+func (b *HSMBuilder) ButtonPayload(index int, payload string) *HSMBuilder {
+	b.buttons[index] = &HSMComponentParameter{Type: HSMComponentParameterTypePayload, Payload: payload}
+	return b
+}
+
+// Build checks the parameters against the template and creates the HSM. The
+// parameters are sent as components if the header or buttons have any, and
+// as plain params otherwise.
+func (b *HSMBuilder) Build() (*HSM, error) {
+	t := b.template
+	if t.Status != "" && t.Status != WhatsAppTemplateStatusApproved {
+		return nil, b.errorf("status is %s, not %s", t.Status, WhatsAppTemplateStatusApproved)
+	}
+
+	if err := b.checkHeader(); err != nil {
+		return nil, err
+	}
+	if err := b.checkBody(); err != nil {
+		return nil, err
+	}
+	if err := b.checkButtons(); err != nil {
+		return nil, err
+	}
+
+	hsm := &HSM{
+		Namespace:    t.Namespace,
+		TemplateName: t.Name,
+		Language: &HSMLanguage{
+			Policy: b.policy,
+			Code:   t.Language,
+		},
+	}
+
+	if b.header == nil && len(b.buttons) == 0 {
+		hsm.LocalizableParameters = b.body
+		return hsm, nil
+	}
+
+	if b.header != nil {
+		hsm.Components = append(hsm.Components, &HSMComponent{
+			Type:       HSMComponentTypeHeader,
+			Parameters: []*HSMComponentParameter{b.header},
+		})
+	}
+
+	if len(b.body) > 0 {
+		body := &HSMComponent{Type: HSMComponentTypeBody}
+		for _, param := range b.body {
+			body.Parameters = append(body.Parameters, componentParameter(param))
+		}
+		hsm.Components = append(hsm.Components, body)
+	}
+
+	buttons := b.template.Component(WhatsAppTemplateComponentButtons)
+	for _, index := range b.buttonIndexes() {
+		index := index
+		hsm.Components = append(hsm.Components, &HSMComponent{
+			Type:       HSMComponentTypeButton,
+			SubType:    strings.ToLower(string(buttons.Buttons[index].Type)),
+			Index:      &index,
+			Parameters: []*HSMComponentParameter{b.buttons[index]},
+		})
+	}
+
+	return hsm, nil
+}
+
+func (b *HSMBuilder) checkHeader() error {
+	header := b.template.Component(WhatsAppTemplateComponentHeader)
+	if header == nil {
+		if b.header != nil {
+			return b.errorf("has no header")
+		}
+		return nil
+	}
+
+	switch header.Format {
+	case WhatsAppTemplateHeaderText, "":
+		placeholders := header.Placeholders()
+		if placeholders > 0 && (b.header == nil || b.header.Type != HSMComponentParameterTypeText) {
+			return b.errorf("header needs a text parameter")
+		}
+		if placeholders == 0 && b.header != nil {
+			return b.errorf("header has no placeholders")
+		}
+	default:
+		want := HSMComponentParameterType(strings.ToLower(string(header.Format)))
+		if b.header == nil || b.header.Type != want {
+			return b.errorf("header needs a parameter of type %s", want)
+		}
+	}
+
+	return nil
+}
+
+func (b *HSMBuilder) checkBody() error {
+	var placeholders int
+	if body := b.template.Component(WhatsAppTemplateComponentBody); body != nil {
+		placeholders = body.Placeholders()
+	}
+
+	if len(b.body) != placeholders {
+		return b.errorf("body has %d placeholders, but %d parameters were given", placeholders, len(b.body))
+	}
+
+	return nil
+}
+
+func (b *HSMBuilder) checkButtons() error {
+	var buttons []*WhatsAppTemplateButton
+	if component := b.template.Component(WhatsAppTemplateComponentButtons); component != nil {
+		buttons = component.Buttons
+	}
+
+	for _, index := range b.buttonIndexes() {
+		param := b.buttons[index]
+		if index < 0 || index >= len(buttons) {
+			return b.errorf("has no button %d", index)
+		}
+
+		switch buttons[index].Type {
+		case WhatsAppTemplateButtonURL:
+			if countPlaceholders(buttons[index].URL) == 0 {
+				return b.errorf("URL of button %d has no placeholder", index)
+			}
+			if param.Type != HSMComponentParameterTypeText {
+				return b.errorf("button %d needs a URL parameter", index)
+			}
+		case WhatsAppTemplateButtonQuickReply:
+			if param.Type != HSMComponentParameterTypePayload {
+				return b.errorf("button %d needs a payload parameter", index)
+			}
+		default:
+			return b.errorf("button %d has no parameters", index)
+		}
+	}
+
+	for index, button := range buttons {
+		if button.Type == WhatsAppTemplateButtonURL && countPlaceholders(button.URL) > 0 && b.buttons[index] == nil {
+			return b.errorf("button %d needs a URL parameter", index)
+		}
+	}
+
+	return nil
+}
+
+// buttonIndexes gets the indexes of the buttons with a parameter, in order.
+func (b *HSMBuilder) buttonIndexes() []int {
+	indexes := make([]int, 0, len(b.buttons))
+	for index := range b.buttons {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	return indexes
+}
+
+func (b *HSMBuilder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("template %s: %s", b.template.Name, fmt.Sprintf(format, args...))
+}
+
+// componentParameter converts a body parameter to a component parameter.
+func componentParameter(param *HSMLocalizableParameter) *HSMComponentParameter {
+	switch {
+	case param.Currency != nil:
+		return &HSMComponentParameter{Type: HSMComponentParameterTypeCurrency, Text: param.Default, Currency: param.Currency}
+	case param.DateTime != nil:
+		return &HSMComponentParameter{Type: HSMComponentParameterTypeDateTime, Text: param.Default, DateTime: param.DateTime}
+	default:
+		return &HSMComponentParameter{Type: HSMComponentParameterTypeText, Text: param.Default}
+	}
+}
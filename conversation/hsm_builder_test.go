@@ -0,0 +1,117 @@
+package conversation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTemplate(t *testing.T) *WhatsAppTemplate {
+	template := &WhatsAppTemplate{}
+	assert.NoError(t, json.Unmarshal(mbtest.Testdata(t, "whatsAppTemplateObject.json"), template))
+
+	return template
+}
+
+func TestHSMBuilderParams(t *testing.T) {
+	template := &WhatsAppTemplate{
+		Name:      "welcome",
+		Language:  "en",
+		Namespace: "nsid",
+		Components: []*WhatsAppTemplateComponent{
+			{Type: WhatsAppTemplateComponentBody, Text: "Welcome, {{1}}!"},
+		},
+	}
+
+	hsm, err := NewHSMBuilder(template).
+		LanguagePolicy(HSMLanguagePolicyFallback).
+		Body(DefaultLocalizableHSMParameter("Jen")).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &HSM{
+		Namespace:             "nsid",
+		TemplateName:          "welcome",
+		Language:              &HSMLanguage{Policy: HSMLanguagePolicyFallback, Code: "en"},
+		LocalizableParameters: []*HSMLocalizableParameter{{Default: "Jen"}},
+	}, hsm)
+}
+
+func TestHSMBuilderComponents(t *testing.T) {
+	hsm, err := NewHSMBuilder(testTemplate(t)).
+		HeaderImage("https://example.com/order.jpg").
+		Body(DefaultLocalizableHSMParameter("Jen"), DefaultLocalizableHSMParameter("1234")).
+		ButtonURL(0, "1234").
+		ButtonPayload(1, "stop").
+		Build()
+	assert.NoError(t, err)
+	assert.Nil(t, hsm.LocalizableParameters)
+
+	data, err := json.Marshal(hsm.Components)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"type": "header", "parameters": [{"type": "image", "image": {"url": "https://example.com/order.jpg"}}]},
+		{"type": "body", "parameters": [{"type": "text", "text": "Jen"}, {"type": "text", "text": "1234"}]},
+		{"type": "button", "sub_type": "url", "index": 0, "parameters": [{"type": "text", "text": "1234"}]},
+		{"type": "button", "sub_type": "quick_reply", "index": 1, "parameters": [{"type": "payload", "payload": "stop"}]}
+	]`, string(data))
+}
+
+func TestHSMBuilderErrors(t *testing.T) {
+	jen := DefaultLocalizableHSMParameter("Jen")
+
+	cases := []struct {
+		name    string
+		builder *HSMBuilder
+		wantErr string
+	}{
+		{
+			"missing body parameter",
+			NewHSMBuilder(testTemplate(t)).HeaderImage("https://example.com/order.jpg").Body(jen).ButtonURL(0, "1234"),
+			"template order_shipped: body has 2 placeholders, but 1 parameters were given",
+		},
+		{
+			"wrong header",
+			NewHSMBuilder(testTemplate(t)).HeaderText("Hello").Body(jen, jen).ButtonURL(0, "1234"),
+			"template order_shipped: header needs a parameter of type image",
+		},
+		{
+			"missing URL parameter",
+			NewHSMBuilder(testTemplate(t)).HeaderImage("https://example.com/order.jpg").Body(jen, jen),
+			"template order_shipped: button 0 needs a URL parameter",
+		},
+		{
+			"several invalid buttons",
+			NewHSMBuilder(testTemplate(t)).HeaderImage("https://example.com/order.jpg").Body(jen, jen).ButtonPayload(0, "1234").ButtonURL(1, "stop").ButtonPayload(5, "stop"),
+			"template order_shipped: button 0 needs a URL parameter",
+		},
+		{
+			"unknown button",
+			NewHSMBuilder(testTemplate(t)).HeaderImage("https://example.com/order.jpg").Body(jen, jen).ButtonURL(0, "1234").ButtonPayload(2, "stop"),
+			"template order_shipped: has no button 2",
+		},
+		{
+			"payload for URL button",
+			NewHSMBuilder(testTemplate(t)).HeaderImage("https://example.com/order.jpg").Body(jen, jen).ButtonPayload(0, "1234"),
+			"template order_shipped: button 0 needs a URL parameter",
+		},
+		{
+			"not approved",
+			NewHSMBuilder(&WhatsAppTemplate{Name: "welcome", Status: WhatsAppTemplateStatusPending}),
+			"template welcome: status is PENDING, not APPROVED",
+		},
+		{
+			"no header",
+			NewHSMBuilder(&WhatsAppTemplate{Name: "welcome"}).HeaderText("Hello"),
+			"template welcome: has no header",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.builder.Build()
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package conversation
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"sync"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// StateStore stores state per conversation for an InboundRouter, e.g. the
+// step a customer is at in a flow. Implementations must be safe for
+// concurrent use. MemoryStateStore is an in-memory implementation.
+type StateStore interface {
+	// Load gets the state of a conversation. It returns nil if there is none.
+	Load(ctx context.Context, conversationID string) (map[string]string, error)
+
+	// Save stores the state of a conversation. Saving an empty state may
+	// remove it.
+	Save(ctx context.Context, conversationID string, state map[string]string) error
+}
+
+// MemoryStateStore is a StateStore that keeps state in memory, so it is lost
+// on restart and not shared between instances.
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	states map[string]map[string]string
+}
+
+// NewMemoryStateStore creates an empty MemoryStateStore.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		states: make(map[string]map[string]string),
+	}
+}
+
+// Load implements StateStore.
+func (s *MemoryStateStore) Load(ctx context.Context, conversationID string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyState(s.states[conversationID]), nil
+}
+
+// Save implements StateStore.
+func (s *MemoryStateStore) Save(ctx context.Context, conversationID string, state map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(state) == 0 {
+		delete(s.states, conversationID)
+		return nil
+	}
+
+	s.states[conversationID] = copyState(state)
+	return nil
+}
+
+// RouteMatch selects the inbound messages a route handles. Empty fields match
+// any message, so an empty RouteMatch matches all messages.
+type RouteMatch struct {
+	ChannelID string
+	Platform  Platform
+	Type      MessageType
+
+	// Text matches the text of the message: the text of a text message, the
+	// caption of media, or the text of an interactive reply. Its submatches
+	// are passed to the handler in RouteContext.Matches.
+	Text *regexp.Regexp
+
+	// ReplyID matches the option a customer selected, as decoded by
+	// DecodeReply.
+	ReplyID string
+}
+
+// RouteFunc handles an inbound message.
+type RouteFunc func(ctx context.Context, rc *RouteContext) error
+
+// RouteContext is the message a RouteFunc handles, with helpers to reply to
+// it and to keep state for its conversation.
+type RouteContext struct {
+	Event          *MessageEvent
+	Message        *Message
+	ConversationID string
+
+	// Matches holds the submatches of RouteMatch.Text, if it was set.
+	Matches []string
+
+	// ReplyEvent is the option the customer selected, or nil if the message is
+	// not a reply to an option.
+	ReplyEvent *ReplyEvent
+
+	// State is the state of the conversation. Changes are saved when the
+	// handler returns without error.
+	State map[string]string
+
+	client messagebird.Client
+}
+
+// Reply sends a message to the conversation. It can be called with the
+// message constructors, e.g. rc.Reply(conversation.TextMessage("Hello")).
+func (rc *RouteContext) Reply(messageType MessageType, content *MessageContent) (*Message, error) {
+	return rc.ReplyWith(&ReplyRequest{Type: messageType, Content: content})
+}
+
+// ReplyWith sends a message to the conversation, for replies that need more
+// than a type and content, like a Tag or a ChannelID.
+func (rc *RouteContext) ReplyWith(req *ReplyRequest) (*Message, error) {
+	return Reply(rc.client, rc.ConversationID, req)
+}
+
+// InboundRouter routes messages received from customers to handlers, for
+// bots that answer them. Its HandleMessageCreated can be passed to
+// WebhookHandler.OnMessageCreated:
+//
+//	router := conversation.NewInboundRouter(client, nil)
+//	router.Handle(&conversation.RouteMatch{Text: regexp.MustCompile(`(?i)^hi`)}, func(ctx context.Context, rc *conversation.RouteContext) error {
+//		_, err := rc.Reply(conversation.TextMessage("Hello!"))
+//		return err
+//	})
+//	handler.OnMessageCreated(router.HandleMessageCreated)
+//
+// Routes are tried in the order they were added, and the first one that
+// matches handles the message. Messages of the same conversation are handled
+// one at a time, so handlers can update State safely.
+type InboundRouter struct {
+	Client messagebird.Client
+
+	// Store defaults to a MemoryStateStore.
+	Store StateStore
+
+	mu       sync.RWMutex
+	routes   []*inboundRoute
+	fallback RouteFunc
+
+	locksMu sync.Mutex
+	locks   map[string]*conversationLock
+}
+
+type inboundRoute struct {
+	match *RouteMatch
+	fn    RouteFunc
+}
+
+type conversationLock struct {
+	mu    sync.Mutex
+	users int
+}
+
+// NewInboundRouter creates an InboundRouter that replies with c and keeps
+// state in store. If store is nil, a MemoryStateStore is used.
+func NewInboundRouter(c messagebird.Client, store StateStore) *InboundRouter {
+	if store == nil {
+		store = NewMemoryStateStore()
+	}
+
+	return &InboundRouter{
+		Client: c,
+		Store:  store,
+		locks:  make(map[string]*conversationLock),
+	}
+}
+
+// Handle adds a route that calls fn for messages that match.
+func (r *InboundRouter) Handle(match *RouteMatch, fn RouteFunc) {
+	if match == nil {
+		match = &RouteMatch{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.routes = append(r.routes, &inboundRoute{match: match, fn: fn})
+}
+
+// Default sets the handler for messages that match no route.
+func (r *InboundRouter) Default(fn RouteFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fallback = fn
+}
+
+// HandleMessageCreated routes the message of a message.created event. Only
+// messages received from customers are routed; messages that were sent are
+// ignored.
+func (r *InboundRouter) HandleMessageCreated(ctx context.Context, event *MessageEvent) error {
+	message := event.Message
+	if message == nil || message.Direction != MessageDirectionReceived {
+		return nil
+	}
+
+	rc := &RouteContext{
+		Event:          event,
+		Message:        message,
+		ConversationID: message.ConversationID,
+		client:         r.Client,
+	}
+	if rc.ConversationID == "" && event.Conversation != nil {
+		rc.ConversationID = event.Conversation.ID
+	}
+	if reply, err := DecodeReply(message); err == nil {
+		rc.ReplyEvent = reply
+	}
+
+	fn := r.route(rc)
+	if fn == nil {
+		return nil
+	}
+
+	unlock := r.lock(rc.ConversationID)
+	defer unlock()
+
+	store := r.store()
+	state, err := store.Load(ctx, rc.ConversationID)
+	if err != nil {
+		return err
+	}
+	rc.State = copyState(state)
+	if rc.State == nil {
+		rc.State = make(map[string]string)
+	}
+
+	if err := fn(ctx, rc); err != nil {
+		return err
+	}
+
+	if (len(rc.State) == 0 && len(state) == 0) || reflect.DeepEqual(rc.State, state) {
+		return nil
+	}
+
+	return store.Save(ctx, rc.ConversationID, rc.State)
+}
+
+// store gets the Store, and sets it to a MemoryStateStore if it is nil.
+func (r *InboundRouter) store() StateStore {
+	r.locksMu.Lock()
+	defer r.locksMu.Unlock()
+
+	if r.Store == nil {
+		r.Store = NewMemoryStateStore()
+	}
+
+	return r.Store
+}
+
+// route finds the handler for rc and sets its Matches.
+func (r *InboundRouter) route(rc *RouteContext) RouteFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, route := range r.routes {
+		if matches, ok := route.match.matches(rc); ok {
+			rc.Matches = matches
+			return route.fn
+		}
+	}
+
+	return r.fallback
+}
+
+func (m *RouteMatch) matches(rc *RouteContext) ([]string, bool) {
+	message := rc.Message
+	if m.ChannelID != "" && m.ChannelID != message.ChannelID {
+		return nil, false
+	}
+	if m.Platform != "" && string(m.Platform) != message.Platform {
+		return nil, false
+	}
+	if m.Type != "" && m.Type != message.Type {
+		return nil, false
+	}
+	if m.ReplyID != "" && (rc.ReplyEvent == nil || rc.ReplyEvent.ID != m.ReplyID) {
+		return nil, false
+	}
+
+	if m.Text == nil {
+		return nil, true
+	}
+
+	matches := m.Text.FindStringSubmatch(messageText(message.Content))
+	return matches, matches != nil
+}
+
+// lock locks the conversation and returns the function that unlocks it.
+func (r *InboundRouter) lock(conversationID string) func() {
+	r.locksMu.Lock()
+	if r.locks == nil {
+		r.locks = make(map[string]*conversationLock)
+	}
+	l, ok := r.locks[conversationID]
+	if !ok {
+		l = &conversationLock{}
+		r.locks[conversationID] = l
+	}
+	l.users++
+	r.locksMu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		r.locksMu.Lock()
+		l.users--
+		if l.users == 0 {
+			delete(r.locks, conversationID)
+		}
+		r.locksMu.Unlock()
+	}
+}
+
+func copyState(state map[string]string) map[string]string {
+	if state == nil {
+		return nil
+	}
+
+	c := make(map[string]string, len(state))
+	for k, v := range state {
+		c[k] = v
+	}
+
+	return c
+}
@@ -0,0 +1,124 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func inboundEvent(t *testing.T, id string, content string) *MessageEvent {
+	message := &Message{}
+	assert.NoError(t, json.Unmarshal([]byte(content), message))
+	message.ID = id
+	message.ConversationID = "convid"
+	message.Direction = MessageDirectionReceived
+
+	return &MessageEvent{Type: WebhookEventMessageCreated, Message: message}
+}
+
+func TestInboundRouter(t *testing.T) {
+	var mu sync.Mutex
+	var replies []string
+	transport, teardown := mbtest.HTTPTestTransport(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &ReplyRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		replies = append(replies, r.URL.Path+" "+req.Content.Text)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "msgid"}`))
+	}))
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	var handled []string
+	router := NewInboundRouter(client, nil)
+	router.Handle(&RouteMatch{ReplyID: "yes"}, func(ctx context.Context, rc *RouteContext) error {
+		handled = append(handled, "yes "+rc.ReplyEvent.Title)
+		delete(rc.State, "step")
+		return nil
+	})
+	router.Handle(&RouteMatch{Platform: PlatformWhatsApp, Text: regexp.MustCompile(`(?i)^order (\d+)`)}, func(ctx context.Context, rc *RouteContext) error {
+		handled = append(handled, "order "+rc.Matches[1])
+		rc.State["step"] = "confirm"
+		_, err := rc.Reply(TextMessage("Confirm order " + rc.Matches[1] + "?"))
+		return err
+	})
+	router.Handle(&RouteMatch{Type: MessageTypeImage}, func(ctx context.Context, rc *RouteContext) error {
+		handled = append(handled, "image")
+		return nil
+	})
+	router.Default(func(ctx context.Context, rc *RouteContext) error {
+		handled = append(handled, "default "+rc.State["step"])
+		return nil
+	})
+
+	ctx := context.Background()
+	assert.NoError(t, router.HandleMessageCreated(ctx, inboundEvent(t, "m1", `{"platform": "whatsapp", "type": "text", "content": {"text": "Order 42"}}`)))
+	assert.NoError(t, router.HandleMessageCreated(ctx, inboundEvent(t, "m2", `{"platform": "sms", "type": "text", "content": {"text": "order 43"}}`)))
+	assert.NoError(t, router.HandleMessageCreated(ctx, inboundEvent(t, "m3", `{"platform": "whatsapp", "type": "interactive", "content": {"interactive": {"type": "button_reply", "reply": {"id": "yes", "text": "Yes"}}}}`)))
+	assert.NoError(t, router.HandleMessageCreated(ctx, inboundEvent(t, "m4", `{"platform": "whatsapp", "type": "image", "content": {"image": {"url": "https://example.com/cat.jpg"}}}`)))
+	assert.NoError(t, router.HandleMessageCreated(ctx, inboundEvent(t, "m5", `{"platform": "whatsapp", "type": "text", "content": {"text": "thanks"}}`)))
+
+	sent := inboundEvent(t, "m6", `{"platform": "whatsapp", "type": "text", "content": {"text": "Order 44"}}`)
+	sent.Message.Direction = MessageDirectionSent
+	assert.NoError(t, router.HandleMessageCreated(ctx, sent))
+
+	assert.Equal(t, []string{"order 42", "default confirm", "yes Yes", "image", "default "}, handled)
+	assert.Equal(t, []string{"/v1/conversations/convid/messages Confirm order 42?"}, replies)
+
+	state, err := router.Store.Load(ctx, "convid")
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestInboundRouterHandlerError(t *testing.T) {
+	store := NewMemoryStateStore()
+	router := NewInboundRouter(mbtest.Client(t), store)
+
+	errHandler := errors.New("handler failed")
+	router.Handle(nil, func(ctx context.Context, rc *RouteContext) error {
+		rc.State["step"] = "started"
+		return errHandler
+	})
+
+	ctx := context.Background()
+	err := router.HandleMessageCreated(ctx, inboundEvent(t, "m1", `{"type": "text", "content": {"text": "hi"}}`))
+	assert.Equal(t, errHandler, err)
+
+	state, err := store.Load(ctx, "convid")
+	assert.NoError(t, err)
+	assert.Nil(t, state, "state must not be saved when the handler fails")
+}
+
+func TestInboundRouterZeroValue(t *testing.T) {
+	router := &InboundRouter{Client: mbtest.Client(t)}
+
+	var steps []string
+	router.Handle(nil, func(ctx context.Context, rc *RouteContext) error {
+		steps = append(steps, rc.State["step"])
+		rc.State["step"] = "started"
+		return nil
+	})
+
+	ctx := context.Background()
+	assert.NoError(t, router.HandleMessageCreated(ctx, inboundEvent(t, "m1", `{"type": "text", "content": {"text": "hi"}}`)))
+	assert.NoError(t, router.HandleMessageCreated(ctx, inboundEvent(t, "m2", `{"type": "text", "content": {"text": "hi"}}`)))
+	assert.Equal(t, []string{"", "started"}, steps)
+	assert.IsType(t, &MemoryStateStore{}, router.Store)
+}
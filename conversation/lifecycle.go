@@ -0,0 +1,256 @@
+package conversation
+
+import (
+	"errors"
+	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/internal/pool"
+)
+
+// DefaultLifecycleConcurrency is the number of conversations that are
+// updated in parallel when no concurrency is given.
+const DefaultLifecycleConcurrency = 5
+
+// lifecyclePageSize is the number of conversations listed per request.
+const lifecyclePageSize = 20
+
+// LifecycleOptions configures ArchiveInactive and ReactivateByContact.
+type LifecycleOptions struct {
+	// DryRun reports what would be done, without updating conversations.
+	DryRun bool
+
+	// Concurrency is the maximum number of conversations that are updated
+	// in parallel. DefaultLifecycleConcurrency is used when it is zero.
+	Concurrency int
+}
+
+// LifecycleAction describes what was done with a conversation.
+type LifecycleAction string
+
+const (
+	LifecycleActionArchived    LifecycleAction = "archived"
+	LifecycleActionReactivated LifecycleAction = "reactivated"
+	LifecycleActionSkipped     LifecycleAction = "skipped"
+	LifecycleActionFailed      LifecycleAction = "failed"
+)
+
+// LifecycleResult is the outcome for a single conversation. On a dry run,
+// Action is what would have been done.
+type LifecycleResult struct {
+	ConversationID string
+	ContactID      string
+	Action         LifecycleAction
+
+	// Err is set for LifecycleActionFailed.
+	Err error
+}
+
+// LifecycleReport summarizes a bulk update.
+type LifecycleReport struct {
+	DryRun bool
+
+	Archived    int
+	Reactivated int
+	Skipped     int
+	Failed      int
+
+	// Results holds the result of every conversation, in the order they were
+	// listed.
+	Results []*LifecycleResult
+}
+
+func (r *LifecycleReport) add(result *LifecycleResult) {
+	switch result.Action {
+	case LifecycleActionArchived:
+		r.Archived++
+	case LifecycleActionReactivated:
+		r.Reactivated++
+	case LifecycleActionSkipped:
+		r.Skipped++
+	case LifecycleActionFailed:
+		r.Failed++
+	}
+
+	r.Results = append(r.Results, result)
+}
+
+// ArchiveInactive archives the active conversations that have not received a
+// message for longer than inactiveFor. Conversations that never received a
+// message are judged by when they were created.
+//
+// All active conversations are listed before any is archived, so archiving
+// doesn't shift the pages that are still to be listed. Errors for individual
+// conversations are listed in the report. An error is only returned if
+// inactiveFor is not positive, or the conversations can't be listed.
+func ArchiveInactive(c messagebird.Client, inactiveFor time.Duration, options *LifecycleOptions) (*LifecycleReport, error) {
+	return archiveInactive(c, inactiveFor, options, time.Now())
+}
+
+// archiveInactive is ArchiveInactive, measuring inactivity up to now.
+func archiveInactive(c messagebird.Client, inactiveFor time.Duration, options *LifecycleOptions, now time.Time) (*LifecycleReport, error) {
+	if inactiveFor <= 0 {
+		return nil, errors.New("inactiveFor must be positive")
+	}
+
+	options = lifecycleOptions(options)
+	cutoff := now.Add(-inactiveFor)
+
+	status := ConversationStatusActive
+	req := &ListRequest{Status: &status}
+	req.Limit = lifecyclePageSize
+
+	var inactive []*Conversation
+	for {
+		conversations, err := List(c, req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, conv := range conversations.Items {
+			lastActive := conv.CreatedDatetime
+			if conv.LastReceivedDatetime != nil {
+				lastActive = *conv.LastReceivedDatetime
+			}
+			if conv.Status == ConversationStatusActive && lastActive.Before(cutoff) {
+				inactive = append(inactive, conv)
+			}
+		}
+
+		req.Offset += len(conversations.Items)
+		if len(conversations.Items) == 0 || req.Offset >= conversations.TotalCount {
+			break
+		}
+	}
+
+	results := make([]*LifecycleResult, len(inactive))
+	pool.Run(len(inactive), options.Concurrency, func(i int) {
+		result := &LifecycleResult{
+			ConversationID: inactive[i].ID,
+			ContactID:      inactive[i].ContactID,
+			Action:         LifecycleActionArchived,
+		}
+		if !options.DryRun {
+			if _, err := Update(c, inactive[i].ID, &UpdateRequest{Status: ConversationStatusArchived}); err != nil {
+				result.Action, result.Err = LifecycleActionFailed, err
+			}
+		}
+		results[i] = result
+	})
+
+	return lifecycleReport(options, results), nil
+}
+
+// ReactivateByContact reactivates a conversation of each of contactIDs. Only
+// one conversation of a contact can be active, so the conversation that
+// received a message most recently is reactivated and the others are
+// skipped. Contacts that already have an active conversation are skipped.
+//
+// Errors for individual contacts are listed in the report, with the
+// ContactID set.
+func ReactivateByContact(c messagebird.Client, contactIDs []string, options *LifecycleOptions) (*LifecycleReport, error) {
+	options = lifecycleOptions(options)
+
+	results := make([][]*LifecycleResult, len(contactIDs))
+	pool.Run(len(contactIDs), options.Concurrency, func(i int) {
+		results[i] = reactivateContact(c, contactIDs[i], options.DryRun)
+	})
+
+	var flat []*LifecycleResult
+	for _, contactResults := range results {
+		flat = append(flat, contactResults...)
+	}
+
+	return lifecycleReport(options, flat), nil
+}
+
+func reactivateContact(c messagebird.Client, contactID string, dryRun bool) []*LifecycleResult {
+	failed := func(err error) []*LifecycleResult {
+		return []*LifecycleResult{{ContactID: contactID, Action: LifecycleActionFailed, Err: err}}
+	}
+
+	var conversations []*Conversation
+	pagination := &messagebird.PaginationRequest{Limit: lifecyclePageSize}
+	for {
+		byContact, err := ListByContact(c, contactID, pagination)
+		if err != nil {
+			return failed(err)
+		}
+
+		for _, id := range byContact.Items {
+			if id == nil {
+				continue
+			}
+			conv, err := Read(c, *id)
+			if err != nil {
+				return failed(err)
+			}
+			conversations = append(conversations, conv)
+		}
+
+		pagination.Offset += len(byContact.Items)
+		if len(byContact.Items) == 0 || pagination.Offset >= byContact.TotalCount {
+			break
+		}
+	}
+
+	var latest *Conversation
+	hasActive := false
+	for _, conv := range conversations {
+		if conv.Status == ConversationStatusActive {
+			hasActive = true
+		}
+		if latest == nil || lastReceived(conv).After(lastReceived(latest)) {
+			latest = conv
+		}
+	}
+
+	results := make([]*LifecycleResult, 0, len(conversations))
+	for _, conv := range conversations {
+		result := &LifecycleResult{
+			ConversationID: conv.ID,
+			ContactID:      contactID,
+			Action:         LifecycleActionSkipped,
+		}
+		if conv == latest && !hasActive {
+			result.Action = LifecycleActionReactivated
+			if !dryRun {
+				if _, err := Update(c, conv.ID, &UpdateRequest{Status: ConversationStatusActive}); err != nil {
+					result.Action, result.Err = LifecycleActionFailed, err
+				}
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func lastReceived(conv *Conversation) time.Time {
+	if conv.LastReceivedDatetime != nil {
+		return *conv.LastReceivedDatetime
+	}
+
+	return conv.CreatedDatetime
+}
+
+func lifecycleOptions(options *LifecycleOptions) *LifecycleOptions {
+	o := LifecycleOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultLifecycleConcurrency
+	}
+
+	return &o
+}
+
+func lifecycleReport(options *LifecycleOptions, results []*LifecycleResult) *LifecycleReport {
+	report := &LifecycleReport{DryRun: options.DryRun}
+	for _, result := range results {
+		report.add(result)
+	}
+
+	return report
+}
@@ -0,0 +1,155 @@
+package conversation
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// lifecycleServer serves conversations that can be listed, read and updated.
+type lifecycleServer struct {
+	mu            sync.Mutex
+	conversations []*Conversation
+	updates       []string
+}
+
+func (s *lifecycleServer) add(id, contactID string, status Status, lastReceived string) {
+	t, _ := time.Parse(time.RFC3339, lastReceived)
+	s.conversations = append(s.conversations, &Conversation{
+		ID:                   id,
+		ContactID:            contactID,
+		Status:               status,
+		CreatedDatetime:      t.Add(-time.Hour),
+		LastReceivedDatetime: &t,
+	})
+}
+
+func (s *lifecycleServer) find(id string) *Conversation {
+	for _, conv := range s.conversations {
+		if conv.ID == id {
+			return conv
+		}
+	}
+
+	return nil
+}
+
+func (s *lifecycleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+
+	switch {
+	case r.URL.Path == "/v1/conversations":
+		list := &Conversations{Items: []*Conversation{}}
+		for _, conv := range s.conversations {
+			if string(conv.Status) == r.URL.Query().Get("status") {
+				list.Items = append(list.Items, conv)
+			}
+		}
+		list.Count, list.TotalCount = len(list.Items), len(list.Items)
+		enc.Encode(list)
+	case strings.HasPrefix(r.URL.Path, "/v1/conversations/contact/"):
+		contactID := strings.TrimPrefix(r.URL.Path, "/v1/conversations/contact/")
+		list := &ConversationsByContact{Items: []*string{}}
+		for _, conv := range s.conversations {
+			if conv.ContactID == contactID {
+				id := conv.ID
+				list.Items = append(list.Items, &id)
+			}
+		}
+		list.Count, list.TotalCount = len(list.Items), len(list.Items)
+		enc.Encode(list)
+	case r.Method == http.MethodPatch:
+		conv := s.find(strings.TrimPrefix(r.URL.Path, "/v1/conversations/"))
+		req := &UpdateRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		conv.Status = req.Status
+		s.updates = append(s.updates, conv.ID+":"+string(req.Status))
+		enc.Encode(conv)
+	default:
+		conv := s.find(strings.TrimPrefix(r.URL.Path, "/v1/conversations/"))
+		if conv == nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": [{"code": 404, "description": "conversation not found"}]}`))
+			return
+		}
+		enc.Encode(conv)
+	}
+}
+
+func TestArchiveInactive(t *testing.T) {
+	server := &lifecycleServer{}
+	server.add("stale", "c1", ConversationStatusActive, "2021-01-01T12:00:00Z")
+	server.add("recent", "c2", ConversationStatusActive, "2021-02-28T12:00:00Z")
+	server.add("archived", "c3", ConversationStatusArchived, "2021-01-01T12:00:00Z")
+
+	transport, teardown := mbtest.HTTPTestTransport(server)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	report, err := archiveInactive(client, 7*24*time.Hour, &LifecycleOptions{DryRun: true}, now)
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Archived)
+	assert.Equal(t, "stale", report.Results[0].ConversationID)
+	assert.Empty(t, server.updates)
+
+	report, err = archiveInactive(client, 7*24*time.Hour, nil, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Archived)
+	assert.Equal(t, []string{"stale:archived"}, server.updates)
+
+	for _, inactiveFor := range []time.Duration{0, -time.Hour} {
+		report, err = ArchiveInactive(client, inactiveFor, nil)
+		assert.EqualError(t, err, "inactiveFor must be positive")
+		assert.Nil(t, report)
+	}
+	assert.Equal(t, []string{"stale:archived"}, server.updates)
+}
+
+func TestReactivateByContact(t *testing.T) {
+	server := &lifecycleServer{}
+	server.add("old", "c1", ConversationStatusArchived, "2021-01-01T12:00:00Z")
+	server.add("latest", "c1", ConversationStatusArchived, "2021-02-01T12:00:00Z")
+	server.add("active", "c2", ConversationStatusActive, "2021-02-01T12:00:00Z")
+
+	transport, teardown := mbtest.HTTPTestTransport(server)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	report, err := ReactivateByContact(client, []string{"c1", "c2"}, &LifecycleOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Reactivated)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Empty(t, server.updates)
+
+	report, err = ReactivateByContact(client, []string{"c1", "c2"}, &LifecycleOptions{Concurrency: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*LifecycleResult{
+		{ConversationID: "old", ContactID: "c1", Action: LifecycleActionSkipped},
+		{ConversationID: "latest", ContactID: "c1", Action: LifecycleActionReactivated},
+		{ConversationID: "active", ContactID: "c2", Action: LifecycleActionSkipped},
+	}, report.Results)
+	assert.Equal(t, []string{"latest:active"}, server.updates)
+
+	// The contact now has an active conversation.
+	report, err = ReactivateByContact(client, []string{"c1"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Reactivated)
+	assert.Equal(t, 2, report.Skipped)
+}
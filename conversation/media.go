@@ -0,0 +1,181 @@
+package conversation
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// filesAPIRoot is the absolute URL for hosting files that are sent as media.
+const filesAPIRoot = "https://messaging.messagebird.com/v1/files"
+
+// sniffLen is the number of bytes http.DetectContentType considers.
+const sniffLen = 512
+
+// UploadedMedia is a file hosted by MessageBird.
+type UploadedMedia struct {
+	ID string `json:"id"`
+
+	// URL can be used as the URL of e.g. an Image or File to send the media.
+	URL string `json:"-"`
+}
+
+// DownloadedMedia is media being downloaded. Body must be closed after
+// reading.
+type DownloadedMedia struct {
+	Body io.ReadCloser
+
+	// ContentType is the content type the server sent. If it didn't send a
+	// specific one, it is detected from the content.
+	ContentType string
+
+	// ContentLength is the size in bytes, or -1 if it is unknown.
+	ContentLength int64
+
+	// FileName is the name from the Content-Disposition header, if any.
+	FileName string
+}
+
+// UploadMedia uploads the media in r, so it can be sent in a message without
+// hosting it yourself. If contentType is empty, it is detected from the
+// content. The media is streamed, so r can be e.g. an *os.File.
+func UploadMedia(client *messagebird.DefaultClient, r io.Reader, contentType string) (*UploadedMedia, error) {
+	if contentType == "" {
+		br := bufio.NewReaderSize(r, sniffLen)
+		// A short read just means the file is smaller than sniffLen.
+		head, _ := br.Peek(sniffLen)
+		contentType = http.DetectContentType(head)
+		r = br
+	}
+
+	req, err := newMediaRequest(client, http.MethodPost, filesAPIRoot, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, mediaError(resp)
+	}
+
+	media := &UploadedMedia{}
+	if err := json.NewDecoder(resp.Body).Decode(media); err != nil {
+		return nil, err
+	}
+	media.URL = filesAPIRoot + "/" + media.ID
+
+	return media, nil
+}
+
+// DownloadMedia streams media from url, e.g. the URL of an inbound image or
+// voice note. Inbound media is only accessible with an access key, so it
+// can't be downloaded with a plain HTTP request. The access key is only sent
+// to MessageBird over HTTPS, so media hosted elsewhere can be downloaded
+// too without leaking it.
+func DownloadMedia(client *messagebird.DefaultClient, url string) (*DownloadedMedia, error) {
+	req, err := newMediaRequest(client, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, mediaError(resp)
+	}
+
+	media := &DownloadedMedia{
+		Body:          resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	}
+
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		media.FileName = params["filename"]
+	}
+
+	if media.ContentType == "" || media.ContentType == "application/octet-stream" {
+		br := bufio.NewReaderSize(resp.Body, sniffLen)
+		head, _ := br.Peek(sniffLen)
+		media.ContentType = http.DetectContentType(head)
+		media.Body = struct {
+			io.Reader
+			io.Closer
+		}{br, resp.Body}
+	}
+
+	return media, nil
+}
+
+// MediaURL gets the URL of the media in the content: the image, video, audio,
+// file or WhatsApp sticker. It returns an empty string if the content has no
+// media.
+func (c *MessageContent) MediaURL() string {
+	switch {
+	case c.Image != nil:
+		return c.Image.URL
+	case c.Video != nil:
+		return c.Video.URL
+	case c.Audio != nil:
+		return c.Audio.URL
+	case c.File != nil:
+		return c.File.URL
+	case c.WhatsAppSticker != nil:
+		return c.WhatsAppSticker.Link
+	default:
+		return ""
+	}
+}
+
+func newMediaRequest(client *messagebird.DefaultClient, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if isMessageBirdURL(req.URL) {
+		req.Header.Set("Authorization", "AccessKey "+client.AccessKey)
+	}
+	req.Header.Set("User-Agent", "MessageBird/ApiClient/"+messagebird.ClientVersion+" Go/"+runtime.Version())
+
+	return req, nil
+}
+
+// isMessageBirdURL reports whether u is an HTTPS URL on messagebird.com or one
+// of its subdomains, which the access key may be sent to.
+func isMessageBirdURL(u *url.URL) bool {
+	host := u.Hostname()
+	return u.Scheme == "https" && (host == "messagebird.com" || strings.HasSuffix(host, ".messagebird.com"))
+}
+
+// mediaError gets the error of a response with an unexpected status. Like
+// the API, it returns the errors in the body if it has any.
+func mediaError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusInternalServerError {
+		return messagebird.ErrUnexpectedResponse
+	}
+
+	var errorResponse messagebird.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil || len(errorResponse.Errors) == 0 {
+		return fmt.Errorf("bad HTTP status: %d", resp.StatusCode)
+	}
+
+	return errorResponse
+}
@@ -0,0 +1,147 @@
+package conversation
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// pngHeader is enough of a PNG file to detect its content type.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestUploadMedia(t *testing.T) {
+	var contentType, authorization string
+	var body []byte
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/files", r.URL.Path)
+
+		contentType = r.Header.Get("Content-Type")
+		authorization = r.Header.Get("Authorization")
+		body, _ = ioutil.ReadAll(r.Body)
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"fileid"}`))
+	})
+	transport, teardown := mbtest.HTTPTestTransport(h)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.AccessKey = "test_gshuPaZoeEG6ovbc8M79w0QyM"
+	client.HTTPClient.Transport = transport
+
+	media, err := UploadMedia(client, bytes.NewReader(pngHeader), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "fileid", media.ID)
+	assert.Equal(t, "https://messaging.messagebird.com/v1/files/fileid", media.URL)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, "AccessKey test_gshuPaZoeEG6ovbc8M79w0QyM", authorization)
+	assert.Equal(t, pngHeader, body)
+
+	_, err = UploadMedia(client, bytes.NewReader([]byte("%PDF-1.4")), "application/pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+}
+
+func TestUploadMediaError(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"errors":[{"code":21,"description":"file is too large","parameter":"file"}]}`))
+	})
+	transport, teardown := mbtest.HTTPTestTransport(h)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	_, err := UploadMedia(client, bytes.NewReader(pngHeader), "")
+	assert.EqualError(t, err, "API errors: file is too large")
+	if errorResponse, ok := err.(messagebird.ErrorResponse); assert.True(t, ok) {
+		assert.Equal(t, 21, errorResponse.Errors[0].Code)
+	}
+}
+
+func TestDownloadMedia(t *testing.T) {
+	var authorization string
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+
+		switch r.URL.Path {
+		case "/v1/media/typed":
+			w.Header().Set("Content-Type", "audio/ogg")
+			w.Header().Set("Content-Disposition", `attachment; filename="voice.ogg"`)
+			w.Write([]byte("OggS"))
+		case "/v1/media/untyped":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(pngHeader)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	transport, teardown := mbtest.HTTPTestTransport(h)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.AccessKey = "test_gshuPaZoeEG6ovbc8M79w0QyM"
+	client.HTTPClient.Transport = transport
+
+	media, err := DownloadMedia(client, "https://media.messagebird.com/v1/media/typed")
+	assert.NoError(t, err)
+	assert.Equal(t, "AccessKey test_gshuPaZoeEG6ovbc8M79w0QyM", authorization)
+	assert.Equal(t, "audio/ogg", media.ContentType)
+	assert.Equal(t, "voice.ogg", media.FileName)
+	data, _ := ioutil.ReadAll(media.Body)
+	assert.Equal(t, "OggS", string(data))
+	assert.NoError(t, media.Body.Close())
+
+	media, err = DownloadMedia(client, "https://media.messagebird.com/v1/media/untyped")
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", media.ContentType)
+	data, _ = ioutil.ReadAll(media.Body)
+	assert.Equal(t, pngHeader, data)
+	assert.NoError(t, media.Body.Close())
+
+	_, err = DownloadMedia(client, "https://media.messagebird.com/v1/media/unknown")
+	assert.EqualError(t, err, "bad HTTP status: 404")
+
+	// The access key is not sent to other hosts.
+	media, err = DownloadMedia(client, "https://messagebird.com.example.com/v1/media/typed")
+	assert.NoError(t, err)
+	assert.Equal(t, "", authorization)
+	assert.NoError(t, media.Body.Close())
+}
+
+func TestIsMessageBirdURL(t *testing.T) {
+	for rawURL, expected := range map[string]bool{
+		"https://messagebird.com/media":               true,
+		"https://media.messagebird.com/v1/media/id":   true,
+		"http://media.messagebird.com/v1/media/id":    false,
+		"https://example.com/media":                   false,
+		"https://messagebird.com.example.com/media":   false,
+		"https://notmessagebird.com/v1/media/id":      false,
+		"https://media.messagebird.com:8443/v1/media": true,
+	} {
+		u, err := url.Parse(rawURL)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, isMessageBirdURL(u), rawURL)
+	}
+}
+
+func TestMessageContentMediaURL(t *testing.T) {
+	_, image := ImageMessage("https://example.com/cat.jpg", "")
+	assert.Equal(t, "https://example.com/cat.jpg", image.MediaURL())
+
+	_, sticker := WhatsAppStickerMessage("https://example.com/cat.webp")
+	assert.Equal(t, "https://example.com/cat.webp", sticker.MediaURL())
+
+	_, text := TextMessage("Hello")
+	assert.Equal(t, "", text.MediaURL())
+}
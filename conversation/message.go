@@ -22,6 +22,7 @@ const (
 	MessageStatusAccepted        MessageStatus = "accepted"
 	MessageStatusPending         MessageStatus = "pending"
 	MessageStatusSent            MessageStatus = "sent"
+	MessageStatusDelivered       MessageStatus = "delivered"
 	MessageStatusRejected        MessageStatus = "rejected"
 	MessageStatusFailed          MessageStatus = "failed"
 	MessageStatusRead            MessageStatus = "read"
@@ -61,6 +62,13 @@ const (
 	MessageTypeWhatsappOrder   MessageType = "whatsappOrder"
 	MessageTypeWhatsappText    MessageType = "whatsappText"
 
+	MessageTypeFacebookQuickReply      MessageType = "facebookQuickReply"
+	MessageTypeFacebookMediaTemplate   MessageType = "facebookMediaTemplate"
+	MessageTypeFacebookGenericTemplate MessageType = "facebookGenericTemplate"
+	MessageTypeFacebookButtonTemplate  MessageType = "facebookButtonTemplate"
+	MessageTypeFacebookReceiptTemplate MessageType = "facebookReceiptTemplate"
+	MessageTypeFacebookPostback        MessageType = "facebookPostback"
+
 	MessageTypeExternalAttachment MessageType = "externalAttachment"
 	MessageTypeEmail              MessageType = "email"
 )
@@ -96,6 +104,7 @@ type Message struct {
 	UpdatedDatetime *time.Time
 	Source          map[string]interface{}
 	Tag             MessageTag
+	TrackId         string
 	Fallback        *Fallback
 	TTL             string
 }
@@ -122,6 +131,11 @@ type MessageContent struct {
 	FacebookQuickReply      *FacebookMessage `json:"facebookQuickReply,omitempty"`
 	FacebookMediaTemplate   *FacebookMessage `json:"facebookMediaTemplate,omitempty"`
 	FacebookGenericTemplate *FacebookMessage `json:"facebookGenericTemplate,omitempty"`
+	FacebookButtonTemplate  *FacebookMessage `json:"facebookButtonTemplate,omitempty"`
+	FacebookReceiptTemplate *FacebookMessage `json:"facebookReceiptTemplate,omitempty"`
+
+	// FacebookPostback is only set on inbound messages.
+	FacebookPostback *FacebookPostback `json:"facebookPostback,omitempty"`
 
 	Email               *Email   `json:"email,omitempty"`
 	ExternalAttachments []*Media `json:"externalAttachments,omitempty"`
@@ -182,16 +196,26 @@ func (lr *ListConversationMessagesRequest) QueryParams() string {
 
 	query := url.Values{}
 
-	query.Set("limit", strconv.Itoa(lr.Limit))
-	query.Set("offset", strconv.Itoa(lr.Offset))
-	query.Set("excludePlatforms", lr.ExcludePlatforms)
+	if lr.Limit > 0 {
+		query.Set("limit", strconv.Itoa(lr.Limit))
+	}
+	if lr.Offset > 0 {
+		query.Set("offset", strconv.Itoa(lr.Offset))
+	}
+	if lr.ExcludePlatforms != "" {
+		query.Set("excludePlatforms", lr.ExcludePlatforms)
+	}
 
 	return query.Encode()
 }
 
+// ListMessagesRequest filters the messages of ListMessages. Messages are
+// listed from From on, if it is set.
 type ListMessagesRequest struct {
 	Ids  string
 	From *time.Time
+
+	messagebird.PaginationRequest
 }
 
 func (lr *ListMessagesRequest) QueryParams() string {
@@ -201,10 +225,18 @@ func (lr *ListMessagesRequest) QueryParams() string {
 
 	query := url.Values{}
 
-	query.Set("ids", lr.Ids)
+	if lr.Ids != "" {
+		query.Set("ids", lr.Ids)
+	}
 	if lr.From != nil {
 		query.Set("from", lr.From.Format(time.RFC3339))
 	}
+	if lr.Limit > 0 {
+		query.Set("limit", strconv.Itoa(lr.Limit))
+	}
+	if lr.Offset > 0 {
+		query.Set("offset", strconv.Itoa(lr.Offset))
+	}
 
 	return query.Encode()
 }
@@ -108,6 +108,17 @@ func TestListConversationMessages(t *testing.T) {
 		query := mbtest.Request.URL.RawQuery
 		assert.Equal(t, "", query)
 	})
+
+	t.Run("zero_values", func(t *testing.T) {
+		mbtest.WillReturnTestdata(t, "allMessageListObject.json", http.StatusOK)
+		client := mbtest.Client(t)
+
+		_, err := ListConversationMessages(client, conversationId, &ListConversationMessagesRequest{})
+		assert.NoError(t, err)
+
+		query := mbtest.Request.URL.RawQuery
+		assert.Equal(t, "", query)
+	})
 }
 
 func TestReadMessage(t *testing.T) {
@@ -0,0 +1,65 @@
+package conversation
+
+import "errors"
+
+// ErrNotReply is returned by DecodeReply for messages that are not a reply to
+// buttons or quick replies.
+var ErrNotReply = errors.New("message is not a reply to an option")
+
+// ReplyEvent is an option a customer selected: a WhatsApp reply button or list
+// row, or a Messenger quick reply or postback button.
+type ReplyEvent struct {
+	Platform Platform
+
+	// Type is the type of the inbound message.
+	Type MessageType
+
+	// ID identifies the option that was selected: the id or payload it was
+	// sent with.
+	ID string
+
+	// Title is the text of the option, if the platform reports it.
+	Title string
+
+	MessageID      string
+	ConversationID string
+	From           string
+}
+
+// DecodeReply gets the option that was selected in an inbound message, e.g.
+// from a MessageEvent. It returns ErrNotReply for other messages.
+func DecodeReply(message *Message) (*ReplyEvent, error) {
+	if message == nil || message.Content == nil {
+		return nil, ErrNotReply
+	}
+
+	event := &ReplyEvent{
+		Platform:       Platform(message.Platform),
+		Type:           message.Type,
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		From:           message.From,
+	}
+
+	switch message.Type {
+	case MessageTypeInteractive:
+		reply, err := DecodeWhatsAppInteractiveReply(message)
+		if err == ErrNotInteractiveReply {
+			return nil, ErrNotReply
+		}
+		if err != nil {
+			return nil, err
+		}
+		event.ID, event.Title = reply.ID, reply.Title
+	case MessageTypeFacebookQuickReply, MessageTypeFacebookPostback:
+		reply, err := DecodeFacebookReply(message)
+		if err != nil {
+			return nil, ErrNotReply
+		}
+		event.ID, event.Title = reply.Payload, reply.Title
+	default:
+		return nil, ErrNotReply
+	}
+
+	return event, nil
+}
@@ -0,0 +1,44 @@
+package conversation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeReply(t *testing.T) {
+	cases := []struct {
+		name    string
+		message string
+		want    *ReplyEvent
+	}{
+		{
+			"whatsapp button",
+			`{"platform": "whatsapp", "type": "interactive", "content": {"interactive": {"type": "button_reply", "reply": {"id": "yes", "text": "Yes"}}}}`,
+			&ReplyEvent{Platform: PlatformWhatsApp, Type: MessageTypeInteractive, ID: "yes", Title: "Yes"},
+		},
+		{
+			"facebook postback",
+			`{"platform": "facebook", "type": "facebookPostback", "content": {"facebookPostback": {"title": "Talk to us", "payload": "TALK"}}}`,
+			&ReplyEvent{Platform: PlatformFacebook, Type: MessageTypeFacebookPostback, ID: "TALK", Title: "Talk to us"},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			message := &Message{}
+			assert.NoError(t, json.Unmarshal([]byte(test.message), message))
+
+			reply, err := DecodeReply(message)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, reply)
+		})
+	}
+
+	_, err := DecodeReply(&Message{Type: MessageTypeText, Content: &MessageContent{Text: "Hi"}})
+	assert.Equal(t, ErrNotReply, err)
+
+	_, err = DecodeReply(&Message{Type: MessageTypeFacebookPostback, Content: &MessageContent{}})
+	assert.Equal(t, ErrNotReply, err)
+}
@@ -0,0 +1,268 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// DefaultRoutingPollInterval is how often a Router reads the status of a
+// message when no poll interval is set.
+const DefaultRoutingPollInterval = 5 * time.Second
+
+// ErrNotDelivered is returned by Router.Send when the message was not
+// delivered on any channel.
+var ErrNotDelivered = errors.New("message was not delivered on any channel")
+
+// RoutingStep is a channel a Router tries to deliver a message on.
+type RoutingStep struct {
+	ChannelID string
+
+	// Timeout is how long the Router waits for the message to be delivered
+	// before it fails over to the next step. The last step is also given up
+	// on after its timeout. It must be positive.
+	Timeout time.Duration
+
+	// Type and Content replace the content of the request for this step,
+	// e.g. to send a template on WhatsApp and text on SMS. They are optional.
+	Type    MessageType
+	Content *MessageContent
+}
+
+// RoutingAttempt is the outcome of a single RoutingStep.
+type RoutingAttempt struct {
+	ChannelID string
+
+	// Message is the message that was sent, with the last known status. It
+	// is nil if sending failed.
+	Message *Message
+
+	// Err is the error that occurred sending the message.
+	Err error
+}
+
+// RoutingResult is the result of Router.Send.
+type RoutingResult struct {
+	// Attempts holds every step that was tried, in order.
+	Attempts []*RoutingAttempt
+
+	// Delivered is the attempt that delivered the message, or nil.
+	Delivered *RoutingAttempt
+}
+
+// ChannelID gets the ID of the channel that delivered the message. It
+// returns an empty string if the message was not delivered.
+func (r *RoutingResult) ChannelID() string {
+	if r.Delivered == nil {
+		return ""
+	}
+
+	return r.Delivered.ChannelID
+}
+
+// Router delivers messages according to a routing policy: an ordered list of
+// channels. It sends on the first channel and fails over to the next one when
+// the message fails or is not delivered in time. This is like
+// SendMessageRequest.Fallback, but for any number of channels and with the
+// outcome reported back.
+//
+// The status of sent messages is read every PollInterval. When webhooks are
+// set up, status updates are picked up immediately by passing
+// HandleMessageUpdated to WebhookHandler.OnMessageUpdated.
+type Router struct {
+	Client messagebird.Client
+	Steps  []*RoutingStep
+
+	// PollInterval is how often the status of a message is read. It
+	// defaults to DefaultRoutingPollInterval. If it is negative, statuses
+	// are only updated by HandleMessageUpdated.
+	PollInterval time.Duration
+
+	// DeliveredStatuses are the statuses that count as delivered. They
+	// default to MessageStatusDelivered and MessageStatusRead.
+	DeliveredStatuses []MessageStatus
+
+	// FailedStatuses are the statuses that make the Router fail over
+	// without waiting for the timeout. They default to
+	// MessageStatusRejected, MessageStatusFailed,
+	// MessageStatusDeliveryFailed and MessageStatusExpired.
+	FailedStatuses []MessageStatus
+
+	mu      sync.Mutex
+	waiters map[string]chan *Message
+}
+
+// NewRouter creates a Router that delivers messages on the channels of
+// steps, in order.
+func NewRouter(c messagebird.Client, steps ...*RoutingStep) *Router {
+	return &Router{
+		Client: c,
+		Steps:  steps,
+	}
+}
+
+// Send delivers the message in req according to the routing policy. The From
+// and Fallback of req are ignored: every step sets its own channel. It returns
+// ErrNotDelivered if no step delivered the message, along with the result
+// describing the attempts.
+func (r *Router) Send(ctx context.Context, req *SendMessageRequest) (*RoutingResult, error) {
+	if len(r.Steps) == 0 {
+		return nil, errors.New("router has no steps")
+	}
+	for i, step := range r.Steps {
+		if step.Timeout <= 0 {
+			return nil, fmt.Errorf("routing step %d has no positive timeout", i)
+		}
+	}
+
+	result := &RoutingResult{}
+	for _, step := range r.Steps {
+		stepReq := *req
+		stepReq.From = step.ChannelID
+		stepReq.Fallback = nil
+		if step.Content != nil {
+			stepReq.Type, stepReq.Content = step.Type, step.Content
+		}
+
+		attempt := &RoutingAttempt{ChannelID: step.ChannelID}
+		result.Attempts = append(result.Attempts, attempt)
+
+		var delivered bool
+		attempt.Message, delivered, attempt.Err = r.sendAndWait(ctx, &stepReq, step.Timeout)
+		if delivered {
+			result.Delivered = attempt
+			return result, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	return result, ErrNotDelivered
+}
+
+// HandleMessageUpdated passes the status of a message.updated event to the
+// Send that is waiting for it. It can be passed to
+// WebhookHandler.OnMessageUpdated.
+func (r *Router) HandleMessageUpdated(ctx context.Context, event *MessageEvent) error {
+	if event.Message == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	waiter, ok := r.waiters[event.Message.ID]
+	r.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	// Don't block the webhook: if the waiter has not read the previous
+	// status yet, replace it with this one.
+	for {
+		select {
+		case waiter <- event.Message:
+			return nil
+		default:
+		}
+
+		select {
+		case <-waiter:
+		default:
+		}
+	}
+}
+
+// sendAndWait sends the message and waits until it is delivered, failed or
+// the timeout expires. It returns the message with its last known status.
+func (r *Router) sendAndWait(ctx context.Context, req *SendMessageRequest, timeout time.Duration) (*Message, bool, error) {
+	message, err := SendMessage(r.Client, req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	updates := make(chan *Message, 1)
+	r.mu.Lock()
+	if r.waiters == nil {
+		r.waiters = make(map[string]chan *Message)
+	}
+	r.waiters[message.ID] = updates
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.waiters, message.ID)
+		r.mu.Unlock()
+	}()
+
+	// Updates that arrived before the waiter was registered are missed, so
+	// read the status once now. As when polling, a read error is not fatal.
+	if latest, err := ReadMessage(r.Client, message.ID); err == nil {
+		message.Status = latest.Status
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var poll <-chan time.Time
+	if interval := r.pollInterval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		if hasStatus(r.DeliveredStatuses, defaultDeliveredStatuses, message.Status) {
+			return message, true, nil
+		}
+		if hasStatus(r.FailedStatuses, defaultFailedStatuses, message.Status) {
+			return message, false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return message, false, nil
+		case <-timer.C:
+			return message, false, nil
+		case update := <-updates:
+			message.Status = update.Status
+		case <-poll:
+			// Read errors are not fatal: the status is read again on the
+			// next tick.
+			if latest, err := ReadMessage(r.Client, message.ID); err == nil {
+				message.Status = latest.Status
+			}
+		}
+	}
+}
+
+var (
+	defaultDeliveredStatuses = []MessageStatus{MessageStatusDelivered, MessageStatusRead}
+	defaultFailedStatuses    = []MessageStatus{MessageStatusRejected, MessageStatusFailed, MessageStatusDeliveryFailed, MessageStatusExpired}
+)
+
+func (r *Router) pollInterval() time.Duration {
+	if r.PollInterval == 0 {
+		return DefaultRoutingPollInterval
+	}
+
+	return r.PollInterval
+}
+
+func hasStatus(statuses, defaults []MessageStatus, status MessageStatus) bool {
+	if statuses == nil {
+		statuses = defaults
+	}
+
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
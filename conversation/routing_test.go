@@ -0,0 +1,156 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// routingTestRouter creates a Router for the test server, which has to be set
+// up with the responses for sending and reading messages.
+func routingTestRouter(t *testing.T, steps ...*RoutingStep) *Router {
+	mbtest.ResetRequests()
+
+	router := NewRouter(mbtest.Client(t), steps...)
+	router.PollInterval = time.Millisecond
+
+	return router
+}
+
+// routingSent gets the channel and text of the messages sent since
+// routingTestRouter.
+func routingSent(t *testing.T) []string {
+	var sent []string
+	for _, request := range mbtest.Requests() {
+		if request.Method != http.MethodPost {
+			continue
+		}
+
+		req := &SendMessageRequest{}
+		assert.NoError(t, json.Unmarshal(request.Body, req))
+		sent = append(sent, req.From+":"+req.Content.Text)
+	}
+
+	return sent
+}
+
+func TestRouterSend(t *testing.T) {
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v1/send", "routingSendWhatsAppResponse.json", http.StatusAccepted)
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v1/send", "routingChannelNotFoundError.json", http.StatusUnprocessableEntity)
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v1/send", "routingSendFacebookResponse.json", http.StatusAccepted)
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v1/send", "routingSendSMSResponse.json", http.StatusAccepted)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/v1/messages/msg-whatsapp", "routingMessageWhatsAppSentObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/v1/messages/msg-facebook", "routingMessageFacebookFailedObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/v1/messages/msg-sms", "routingMessageSMSDeliveredObject.json", http.StatusOK)
+	router := routingTestRouter(t,
+		&RoutingStep{ChannelID: "whatsapp", Timeout: 20 * time.Millisecond},
+		&RoutingStep{ChannelID: "unknown", Timeout: time.Minute},
+		&RoutingStep{ChannelID: "facebook", Timeout: time.Minute},
+		&RoutingStep{ChannelID: "sms", Timeout: time.Minute, Type: MessageTypeText, Content: &MessageContent{Text: "Hi"}},
+		&RoutingStep{ChannelID: "email", Timeout: time.Minute},
+	)
+
+	req := &SendMessageRequest{To: "+31612345678", Type: MessageTypeText, Content: &MessageContent{Text: "Hello"}}
+	result, err := router.Send(context.Background(), req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "sms", result.ChannelID())
+	assert.Equal(t, MessageStatusDelivered, result.Delivered.Message.Status)
+	assert.Equal(t, []string{"whatsapp:Hello", "unknown:Hello", "facebook:Hello", "sms:Hi"}, routingSent(t))
+
+	if assert.Len(t, result.Attempts, 4) {
+		assert.Equal(t, MessageStatusSent, result.Attempts[0].Message.Status)
+		assert.EqualError(t, result.Attempts[1].Err, "API errors: channel not found")
+		assert.Equal(t, MessageStatusFailed, result.Attempts[2].Message.Status)
+	}
+}
+
+func TestRouterSendNotDelivered(t *testing.T) {
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v1/send", "routingSendWhatsAppResponse.json", http.StatusAccepted)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/v1/messages/msg-whatsapp", "routingMessageWhatsAppRejectedObject.json", http.StatusOK)
+	router := routingTestRouter(t, &RoutingStep{ChannelID: "whatsapp", Timeout: time.Minute})
+
+	result, err := router.Send(context.Background(), &SendMessageRequest{To: "+31612345678", Type: MessageTypeText, Content: &MessageContent{Text: "Hello"}})
+	assert.Equal(t, ErrNotDelivered, err)
+	assert.Equal(t, "", result.ChannelID())
+	assert.Len(t, result.Attempts, 1)
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/v1/messages/msg-whatsapp")
+}
+
+func TestRouterSendInvalidTimeout(t *testing.T) {
+	router := routingTestRouter(t,
+		&RoutingStep{ChannelID: "whatsapp", Timeout: time.Minute},
+		&RoutingStep{ChannelID: "sms"},
+	)
+
+	_, err := router.Send(context.Background(), &SendMessageRequest{To: "+31612345678", Type: MessageTypeText, Content: &MessageContent{Text: "Hello"}})
+	assert.EqualError(t, err, "routing step 1 has no positive timeout")
+	assert.Empty(t, mbtest.Requests())
+}
+
+func TestRouterHandleMessageUpdated(t *testing.T) {
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v1/send", "routingSendWhatsAppResponse.json", http.StatusAccepted)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/v1/messages/msg-whatsapp", "routingMessageWhatsAppSentObject.json", http.StatusOK)
+	router := routingTestRouter(t, &RoutingStep{ChannelID: "whatsapp", Timeout: time.Minute})
+	router.PollInterval = -1
+
+	go func() {
+		for !router.hasWaiter("msg-whatsapp") {
+			time.Sleep(time.Millisecond)
+		}
+		assert.NoError(t, router.HandleMessageUpdated(context.Background(), &MessageEvent{
+			Type:    WebhookEventMessageUpdated,
+			Message: &Message{ID: "msg-whatsapp", Status: MessageStatusRead},
+		}))
+	}()
+
+	result, err := router.Send(context.Background(), &SendMessageRequest{To: "+31612345678", Type: MessageTypeText, Content: &MessageContent{Text: "Hello"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "whatsapp", result.ChannelID())
+	assert.Equal(t, MessageStatusRead, result.Delivered.Message.Status)
+}
+
+func TestRouterHandleMessageUpdatedReplacesStatus(t *testing.T) {
+	updates := make(chan *Message, 1)
+	router := &Router{waiters: map[string]chan *Message{"msg-whatsapp": updates}}
+
+	for _, status := range []MessageStatus{MessageStatusSent, MessageStatusDelivered, MessageStatusRead} {
+		assert.NoError(t, router.HandleMessageUpdated(context.Background(), &MessageEvent{
+			Type:    WebhookEventMessageUpdated,
+			Message: &Message{ID: "msg-whatsapp", Status: status},
+		}))
+	}
+
+	assert.Equal(t, MessageStatusRead, (<-updates).Status)
+	assert.Empty(t, updates)
+}
+
+func TestRouterSendCanceled(t *testing.T) {
+	mbtest.WillReturnTestdataFor(t, http.MethodPost, "/v1/send", "routingSendWhatsAppResponse.json", http.StatusAccepted)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/v1/messages/msg-whatsapp", "routingMessageWhatsAppSentObject.json", http.StatusOK)
+	router := routingTestRouter(t,
+		&RoutingStep{ChannelID: "whatsapp", Timeout: time.Minute},
+		&RoutingStep{ChannelID: "sms", Timeout: time.Minute},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result, err := router.Send(ctx, &SendMessageRequest{To: "+31612345678", Type: MessageTypeText, Content: &MessageContent{Text: "Hello"}})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, result.Attempts, 1)
+	assert.Equal(t, []string{"whatsapp:Hello"}, routingSent(t))
+}
+
+func (r *Router) hasWaiter(messageID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.waiters[messageID]
+	return ok
+}
@@ -0,0 +1,324 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+const (
+	// DefaultStatusPollInterval is how long a StatusTracker waits before it
+	// reads the status of a message for the first time.
+	DefaultStatusPollInterval = 2 * time.Second
+
+	// DefaultStatusMaxPollInterval is the maximum time between reads. The
+	// interval doubles after every read until it reaches this maximum.
+	DefaultStatusMaxPollInterval = 30 * time.Second
+)
+
+// ErrFinalStatus is returned by StatusTracker.Wait when a message reached a
+// final status before it reached the target status, e.g. when it failed.
+var ErrFinalStatus = errors.New("message reached a final status before the target status")
+
+// ErrNotTracked is returned by StatusTracker.Wait when the message is
+// forgotten while waiting for it.
+var ErrNotTracked = errors.New("message is not tracked")
+
+// StatusSource indicates how a StatusTracker learned about a status.
+type StatusSource string
+
+const (
+	StatusSourceTrack   StatusSource = "track"
+	StatusSourceWebhook StatusSource = "webhook"
+	StatusSourcePoll    StatusSource = "poll"
+)
+
+// StatusChange is an entry in the timeline of a message.
+type StatusChange struct {
+	Status MessageStatus
+	Source StatusSource
+
+	// Time is the updatedDatetime of the message, or when the change was
+	// observed if the message doesn't have one.
+	Time time.Time
+}
+
+// defaultFinalStatuses are the statuses after which a message doesn't change.
+var defaultFinalStatuses = []MessageStatus{
+	MessageStatusRead, MessageStatusRejected, MessageStatusFailed,
+	MessageStatusDeliveryFailed, MessageStatusExpired, MessageStatusDeleted,
+}
+
+// statusProgress orders the statuses of a message on its way to the
+// recipient, so e.g. waiting for delivered is done when the message is read.
+var statusProgress = map[MessageStatus]int{
+	MessageStatusAccepted:    1,
+	MessageStatusPending:     1,
+	MessageStatusBuffered:    1,
+	MessageStatusSent:        2,
+	MessageStatusTransmitted: 2,
+	MessageStatusDispatched:  2,
+	MessageStatusDelivered:   3,
+	MessageStatusRead:        4,
+}
+
+// StatusTracker keeps a timeline of the statuses of messages and waits for
+// them to reach a status. When webhooks are set up, HandleMessageUpdated can
+// be passed to WebhookHandler.OnMessageUpdated, so status changes are picked
+// up immediately. The status is also polled with ReadMessage, with an
+// interval that backs off from PollInterval to MaxPollInterval.
+type StatusTracker struct {
+	Client messagebird.Client
+
+	// PollInterval defaults to DefaultStatusPollInterval. If it is
+	// negative, statuses are only updated by HandleMessageUpdated.
+	PollInterval time.Duration
+
+	// MaxPollInterval defaults to DefaultStatusMaxPollInterval.
+	MaxPollInterval time.Duration
+
+	// FinalStatuses are the statuses Wait stops at. They default to
+	// MessageStatusRead, MessageStatusRejected, MessageStatusFailed,
+	// MessageStatusDeliveryFailed, MessageStatusExpired and
+	// MessageStatusDeleted. Add MessageStatusDelivered for platforms without
+	// read receipts, like SMS.
+	FinalStatuses []MessageStatus
+
+	mu       sync.Mutex
+	messages map[string]*trackedMessage
+
+	// now timestamps status changes of messages without an updatedDatetime.
+	now func() time.Time
+}
+
+type trackedMessage struct {
+	message  *Message
+	timeline []*StatusChange
+
+	// changed is closed and replaced whenever the status changes, so any
+	// number of waiters can be woken up.
+	changed chan struct{}
+}
+
+// NewStatusTracker creates a StatusTracker that reads statuses with c.
+func NewStatusTracker(c messagebird.Client) *StatusTracker {
+	return &StatusTracker{
+		Client:   c,
+		messages: make(map[string]*trackedMessage),
+		now:      time.Now,
+	}
+}
+
+// Track starts tracking a message, e.g. the one returned by SendMessage. Its
+// current status is the first entry of its timeline.
+func (t *StatusTracker) Track(message *Message) {
+	t.track(message, StatusSourceTrack)
+}
+
+// Forget stops tracking a message and discards its timeline. Calls to Wait
+// for the message return ErrNotTracked.
+func (t *StatusTracker) Forget(messageID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tm, ok := t.messages[messageID]; ok {
+		close(tm.changed)
+		delete(t.messages, messageID)
+	}
+}
+
+// Timeline gets the status changes of a message, from old to new. It returns
+// nil if the message is not tracked.
+func (t *StatusTracker) Timeline(messageID string) []*StatusChange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tm, ok := t.messages[messageID]
+	if !ok {
+		return nil
+	}
+
+	timeline := make([]*StatusChange, len(tm.timeline))
+	copy(timeline, tm.timeline)
+
+	return timeline
+}
+
+// HandleMessageUpdated records the status of a message.updated event for a
+// tracked message. It can be passed to WebhookHandler.OnMessageUpdated.
+func (t *StatusTracker) HandleMessageUpdated(ctx context.Context, event *MessageEvent) error {
+	if event.Message != nil {
+		t.record(event.Message, StatusSourceWebhook)
+	}
+
+	return nil
+}
+
+// Wait waits until the message reaches target, or a later status on the way
+// to the recipient: waiting for MessageStatusDelivered is also done when the
+// message is read. If target is empty, Wait waits for any final status. A
+// message that isn't tracked yet is tracked while waiting, and forgotten when
+// Wait returns.
+//
+// It returns the message with its latest status. If the message reached a
+// final status other than target, ErrFinalStatus is returned with it. If the
+// message is forgotten while waiting, ErrNotTracked is returned.
+func (t *StatusTracker) Wait(ctx context.Context, messageID string, target MessageStatus) (*Message, error) {
+	interval := t.PollInterval
+	if interval == 0 {
+		interval = DefaultStatusPollInterval
+	}
+	maxInterval := t.MaxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultStatusMaxPollInterval
+	}
+
+	t.mu.Lock()
+	_, ok := t.messages[messageID]
+	t.mu.Unlock()
+	if !ok {
+		message, err := ReadMessage(t.Client, messageID)
+		if err != nil {
+			return nil, err
+		}
+		t.track(message, StatusSourcePoll)
+		defer t.Forget(messageID)
+	}
+
+	var message *Message
+	for {
+		t.mu.Lock()
+		tm, ok := t.messages[messageID]
+		var changed chan struct{}
+		if ok {
+			message, changed = tm.message, tm.changed
+		}
+		t.mu.Unlock()
+
+		if !ok {
+			return message, ErrNotTracked
+		}
+
+		if message != nil {
+			if target != "" && statusReached(message.Status, target) {
+				return message, nil
+			}
+			if hasStatus(t.FinalStatuses, defaultFinalStatuses, message.Status) {
+				if target == "" {
+					return message, nil
+				}
+				return message, ErrFinalStatus
+			}
+		}
+
+		var timer *time.Timer
+		var poll <-chan time.Time
+		if interval > 0 {
+			timer = time.NewTimer(interval)
+			poll = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			return message, ctx.Err()
+		case <-changed:
+			stopTimer(timer)
+		case <-poll:
+			// Read errors are not fatal: the status is read again after the
+			// next interval.
+			if latest, err := ReadMessage(t.Client, messageID); err == nil {
+				t.record(latest, StatusSourcePoll)
+			}
+
+			interval *= 2
+			if interval > maxInterval {
+				interval = maxInterval
+			}
+		}
+	}
+}
+
+// track starts tracking message, if it isn't tracked yet, and records its
+// status.
+func (t *StatusTracker) track(message *Message, source StatusSource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.update(t.tracked(message.ID), message, source)
+}
+
+// record records the status of message, if it is tracked. A message that was
+// forgotten while it was being read is not tracked again.
+func (t *StatusTracker) record(message *Message, source StatusSource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tm, ok := t.messages[message.ID]; ok {
+		t.update(tm, message, source)
+	}
+}
+
+// update adds the status of message to the timeline of tm, if it changed.
+// Webhooks and reads can arrive out of order, so a status that is behind the
+// current one on the way to the recipient is ignored. t.mu must be held.
+func (t *StatusTracker) update(tm *trackedMessage, message *Message, source StatusSource) {
+	if tm.message != nil {
+		if tm.message.Status == message.Status {
+			return
+		}
+		if progress, ok := statusProgress[message.Status]; ok && progress < statusProgress[tm.message.Status] {
+			return
+		}
+	}
+
+	at := t.now()
+	if message.UpdatedDatetime != nil {
+		at = *message.UpdatedDatetime
+	}
+
+	tm.message = message
+	tm.timeline = append(tm.timeline, &StatusChange{Status: message.Status, Source: source, Time: at})
+
+	close(tm.changed)
+	tm.changed = make(chan struct{})
+}
+
+// tracked gets or creates the tracked message with messageID. t.mu must be
+// held.
+func (t *StatusTracker) tracked(messageID string) *trackedMessage {
+	if t.messages == nil {
+		t.messages = make(map[string]*trackedMessage)
+	}
+	if t.now == nil {
+		t.now = time.Now
+	}
+
+	tm, ok := t.messages[messageID]
+	if !ok {
+		tm = &trackedMessage{changed: make(chan struct{})}
+		t.messages[messageID] = tm
+	}
+
+	return tm
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
+
+func statusReached(status, target MessageStatus) bool {
+	if status == target {
+		return true
+	}
+
+	progress, ok := statusProgress[status]
+	targetProgress, targetOK := statusProgress[target]
+
+	return ok && targetOK && progress >= targetProgress
+}
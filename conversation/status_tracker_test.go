@@ -0,0 +1,153 @@
+package conversation
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func timelineStatuses(timeline []*StatusChange) []MessageStatus {
+	statuses := make([]MessageStatus, 0, len(timeline))
+	for _, change := range timeline {
+		statuses = append(statuses, change.Status)
+	}
+
+	return statuses
+}
+
+func TestStatusTrackerWebhook(t *testing.T) {
+	tracker := NewStatusTracker(mbtest.Client(t))
+	tracker.PollInterval = -1
+	tracker.Track(&Message{ID: "mesid", Status: MessageStatusAccepted})
+
+	done := make(chan *Message)
+	go func() {
+		message, err := tracker.Wait(context.Background(), "mesid", MessageStatusDelivered)
+		assert.NoError(t, err)
+		done <- message
+	}()
+
+	ctx := context.Background()
+	for _, status := range []MessageStatus{MessageStatusSent, MessageStatusSent, MessageStatusRead, MessageStatusDelivered} {
+		assert.NoError(t, tracker.HandleMessageUpdated(ctx, &MessageEvent{Message: &Message{ID: "mesid", Status: status}}))
+	}
+
+	// Read is on the way past delivered, so the wait is over. The delivered
+	// update arrived late and is ignored.
+	message := <-done
+	assert.Equal(t, MessageStatusRead, message.Status)
+
+	timeline := tracker.Timeline("mesid")
+	assert.Equal(t, []MessageStatus{MessageStatusAccepted, MessageStatusSent, MessageStatusRead}, timelineStatuses(timeline))
+	assert.Equal(t, StatusSourceTrack, timeline[0].Source)
+	assert.Equal(t, StatusSourceWebhook, timeline[2].Source)
+
+	// Untracked messages are ignored.
+	assert.NoError(t, tracker.HandleMessageUpdated(ctx, &MessageEvent{Message: &Message{ID: "other", Status: MessageStatusSent}}))
+	assert.Nil(t, tracker.Timeline("other"))
+
+	tracker.Forget("mesid")
+	assert.Nil(t, tracker.Timeline("mesid"))
+}
+
+func TestStatusTrackerPolling(t *testing.T) {
+	var mu sync.Mutex
+	statuses := []string{"pending", "sent", "sent", "failed"}
+	reads := 0
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		status := statuses[len(statuses)-1]
+		if reads < len(statuses) {
+			status = statuses[reads]
+		}
+		reads++
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "mesid", "status": "` + status + `"}`))
+	})
+	transport, teardown := mbtest.HTTPTestTransport(h)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	tracker := NewStatusTracker(client)
+	tracker.PollInterval = time.Millisecond
+	tracker.MaxPollInterval = 4 * time.Millisecond
+	tracker.Track(&Message{ID: "mesid", Status: MessageStatusPending})
+
+	message, err := tracker.Wait(context.Background(), "mesid", MessageStatusDelivered)
+	assert.Equal(t, ErrFinalStatus, err)
+	assert.Equal(t, MessageStatusFailed, message.Status)
+
+	timeline := tracker.Timeline("mesid")
+	assert.Equal(t, []MessageStatus{MessageStatusPending, MessageStatusSent, MessageStatusFailed}, timelineStatuses(timeline))
+	assert.Equal(t, StatusSourcePoll, timeline[1].Source)
+
+	// Without a target, any final status ends the wait. A message that was
+	// not tracked is forgotten afterwards.
+	tracker.Forget("mesid")
+	message, err = tracker.Wait(context.Background(), "mesid", "")
+	assert.NoError(t, err)
+	assert.Equal(t, MessageStatusFailed, message.Status)
+	assert.Nil(t, tracker.Timeline("mesid"))
+}
+
+func TestStatusTrackerForgotten(t *testing.T) {
+	var tracker *StatusTracker
+
+	// The message is forgotten while it is being read, so the read doesn't
+	// track it again.
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracker.Forget("mesid")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "mesid", "status": "delivered"}`))
+	})
+	transport, teardown := mbtest.HTTPTestTransport(h)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	tracker = NewStatusTracker(client)
+	tracker.PollInterval = time.Millisecond
+	tracker.Track(&Message{ID: "mesid", Status: MessageStatusSent})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	message, err := tracker.Wait(ctx, "mesid", MessageStatusDelivered)
+	assert.Equal(t, ErrNotTracked, err)
+	assert.Equal(t, MessageStatusSent, message.Status)
+	assert.Nil(t, tracker.Timeline("mesid"))
+}
+
+func TestStatusTrackerCanceled(t *testing.T) {
+	tracker := NewStatusTracker(mbtest.Client(t))
+	tracker.PollInterval = -1
+	tracker.Track(&Message{ID: "mesid", Status: MessageStatusSent})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	message, err := tracker.Wait(ctx, "mesid", MessageStatusDelivered)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, MessageStatusSent, message.Status)
+}
+
+func TestStatusReached(t *testing.T) {
+	assert.True(t, statusReached(MessageStatusDelivered, MessageStatusDelivered))
+	assert.True(t, statusReached(MessageStatusRead, MessageStatusSent))
+	assert.False(t, statusReached(MessageStatusSent, MessageStatusDelivered))
+	assert.False(t, statusReached(MessageStatusFailed, MessageStatusDelivered))
+	assert.True(t, statusReached(MessageStatusClicked, MessageStatusClicked))
+}
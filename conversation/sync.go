@@ -0,0 +1,269 @@
+package conversation
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// DefaultSyncPageSize is the number of messages that are listed per request
+// by default.
+const DefaultSyncPageSize = 20
+
+// SyncCheckpoint is the high-water mark of a MessageSyncer: the time of the
+// newest message that was returned, and the IDs of the messages with exactly
+// that time. Messages at the mark are listed again by the next sync, and the
+// IDs are used to skip them.
+//
+// Updated and UpdatedIDs are the same for the time messages were last
+// updated, so a MessageSyncer with an UpdateWindow returns a message again
+// only when it changed.
+type SyncCheckpoint struct {
+	From    time.Time
+	SeenIDs []string
+
+	Updated    time.Time
+	UpdatedIDs []string
+}
+
+// CheckpointStore persists SyncCheckpoints, so syncing continues where it
+// left off after a restart. Implementations must be safe for concurrent use.
+// MemoryCheckpointStore is an in-memory implementation.
+type CheckpointStore interface {
+	// Load gets the checkpoint stored for key. It returns nil if there is
+	// none.
+	Load(ctx context.Context, key string) (*SyncCheckpoint, error)
+
+	// Save stores checkpoint for key.
+	Save(ctx context.Context, key string, checkpoint *SyncCheckpoint) error
+}
+
+// MemoryCheckpointStore is a CheckpointStore that keeps checkpoints in
+// memory. It is mostly useful for testing.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]*SyncCheckpoint
+}
+
+// NewMemoryCheckpointStore creates an empty MemoryCheckpointStore.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		checkpoints: make(map[string]*SyncCheckpoint),
+	}
+}
+
+// Load implements CheckpointStore.
+func (s *MemoryCheckpointStore) Load(ctx context.Context, key string) (*SyncCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.checkpoints[key], nil
+}
+
+// Save implements CheckpointStore.
+func (s *MemoryCheckpointStore) Save(ctx context.Context, key string, checkpoint *SyncCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[key] = checkpoint
+	return nil
+}
+
+// MessageSyncer pulls the messages that were created since the previous sync
+// with ListMessages. The checkpoint is only saved after all pages were read,
+// so a failed sync is retried from the same point and no messages are
+// skipped.
+//
+// The API lists messages by the time they were created, so updates to
+// messages from before the checkpoint, e.g. a status changing to read, are
+// only synced within the UpdateWindow. Use the message.updated webhook for
+// updates to older messages.
+type MessageSyncer struct {
+	Client messagebird.Client
+	Store  CheckpointStore
+
+	// Key identifies the checkpoint in the Store, so several syncers can
+	// share a Store.
+	Key string
+
+	// Start is where the first sync starts, when the Store has no
+	// checkpoint yet. If it is zero, all messages are synced.
+	Start time.Time
+
+	// UpdateWindow is how long before the checkpoint messages are listed
+	// again, to return the ones that were updated since they were returned.
+	// Every sync lists all messages of the window, so it is a tradeoff
+	// between the number of requests and how late updates are synced. If it
+	// is zero, only new messages are synced.
+	UpdateWindow time.Duration
+
+	// PageSize defaults to DefaultSyncPageSize.
+	PageSize int
+}
+
+// NewMessageSyncer creates a MessageSyncer that stores its checkpoint as key
+// in store.
+func NewMessageSyncer(c messagebird.Client, store CheckpointStore, key string) *MessageSyncer {
+	return &MessageSyncer{
+		Client: c,
+		Store:  store,
+		Key:    key,
+	}
+}
+
+// Sync gets the messages that were created since the previous call, from old
+// to new, and saves the new checkpoint. With an UpdateWindow, the messages
+// created within it that were updated since the previous call are returned
+// too. A message that was listed several times while paging is returned once,
+// with its latest state.
+func (s *MessageSyncer) Sync(ctx context.Context) ([]*Message, error) {
+	checkpoint, err := s.Store.Load(ctx, s.Key)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint == nil {
+		checkpoint = &SyncCheckpoint{From: s.Start}
+	}
+
+	seen := idSet(checkpoint.SeenIDs)
+	seenUpdates := idSet(checkpoint.UpdatedIDs)
+
+	// Paging can return a message twice when messages are added while
+	// paging, so the latest version of each message is kept.
+	latest := make(map[string]*Message)
+
+	pageSize := s.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultSyncPageSize
+	}
+
+	options := &ListMessagesRequest{}
+	options.Limit = pageSize
+	if !checkpoint.From.IsZero() {
+		from := checkpoint.From.Add(-s.UpdateWindow)
+		options.From = &from
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		page, err := ListMessages(s.Client, options)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, message := range page.Items {
+			t := createdTime(message)
+			if t.Before(checkpoint.From) || (t.Equal(checkpoint.From) && seen[message.ID]) {
+				// The message was returned by a previous sync, so it is only
+				// returned again if it was updated since.
+				if s.UpdateWindow <= 0 || t.Before(s.Start) || !updatedSince(checkpoint, seenUpdates, message) {
+					continue
+				}
+			}
+			if previous, ok := latest[message.ID]; ok && updatedTime(previous).After(updatedTime(message)) {
+				continue
+			}
+			latest[message.ID] = message
+		}
+
+		options.Offset += len(page.Items)
+		if len(page.Items) == 0 || options.Offset >= page.TotalCount {
+			break
+		}
+	}
+
+	messages := make([]*Message, 0, len(latest))
+	for _, message := range latest {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		ti, tj := createdTime(messages[i]), createdTime(messages[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+
+	if len(messages) == 0 {
+		return messages, nil
+	}
+
+	if err := s.Store.Save(ctx, s.Key, nextCheckpoint(checkpoint, messages)); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// updatedSince reports whether message was updated after the messages that
+// were returned up to checkpoint.
+func updatedSince(checkpoint *SyncCheckpoint, seenUpdates map[string]bool, message *Message) bool {
+	u := updatedTime(message)
+	return u.After(checkpoint.Updated) || (u.Equal(checkpoint.Updated) && !seenUpdates[message.ID])
+}
+
+// nextCheckpoint moves checkpoint to the newest of messages.
+func nextCheckpoint(checkpoint *SyncCheckpoint, messages []*Message) *SyncCheckpoint {
+	next := &SyncCheckpoint{}
+	next.From, next.SeenIDs = nextMark(checkpoint.From, checkpoint.SeenIDs, messages, createdTime)
+	next.Updated, next.UpdatedIDs = nextMark(checkpoint.Updated, checkpoint.UpdatedIDs, messages, updatedTime)
+
+	return next
+}
+
+// nextMark moves the mark at from, with the IDs of the messages at it, to the
+// newest time of messages.
+func nextMark(from time.Time, ids []string, messages []*Message, timeOf func(*Message) time.Time) (time.Time, []string) {
+	mark := from
+	for _, message := range messages {
+		if t := timeOf(message); t.After(mark) {
+			mark = t
+		}
+	}
+
+	var next []string
+	if mark.Equal(from) {
+		next = append(next, ids...)
+	}
+	seen := idSet(next)
+	for _, message := range messages {
+		if timeOf(message).Equal(mark) && !seen[message.ID] {
+			next = append(next, message.ID)
+		}
+	}
+
+	return mark, next
+}
+
+func idSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return set
+}
+
+// createdTime is the time ListMessages filters on.
+func createdTime(message *Message) time.Time {
+	if message.CreatedDatetime != nil {
+		return *message.CreatedDatetime
+	}
+
+	return time.Time{}
+}
+
+// updatedTime is when a message was last changed.
+func updatedTime(message *Message) time.Time {
+	if message.UpdatedDatetime != nil {
+		return *message.UpdatedDatetime
+	}
+
+	return createdTime(message)
+}
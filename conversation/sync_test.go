@@ -0,0 +1,196 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// syncServer serves ListMessages from a set of messages that can change
+// between syncs. Like the API, it lists the messages created from the from
+// parameter on, with a precision of seconds.
+type syncServer struct {
+	mu       sync.Mutex
+	messages map[string]*Message
+	queries  []string
+}
+
+func (s *syncServer) set(id, created, updated string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	createdAt, _ := time.Parse(time.RFC3339, created)
+	updatedAt, _ := time.Parse(time.RFC3339, updated)
+	s.messages[id] = &Message{ID: id, Status: MessageStatusDelivered, CreatedDatetime: &createdAt, UpdatedDatetime: &updatedAt}
+}
+
+func (s *syncServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queries = append(s.queries, r.URL.RawQuery)
+
+	var from time.Time
+	if f := r.URL.Query().Get("from"); f != "" {
+		from, _ = time.Parse(time.RFC3339, f)
+	}
+
+	list := &MessageList{Items: []*Message{}}
+	for _, message := range s.messages {
+		if !message.CreatedDatetime.Before(from) {
+			list.Items = append(list.Items, message)
+		}
+	}
+	list.Count, list.TotalCount = len(list.Items), len(list.Items)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		panic(err)
+	}
+}
+
+func syncedIDs(messages []*Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+
+	return ids
+}
+
+func TestMessageSyncer(t *testing.T) {
+	server := &syncServer{messages: make(map[string]*Message)}
+	server.set("m1", "2021-03-01T12:00:00Z", "2021-03-01T12:00:00Z")
+	server.set("m2", "2021-03-01T12:01:00Z", "2021-03-01T12:05:00Z")
+
+	transport, teardown := mbtest.HTTPTestTransport(server)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	store := NewMemoryCheckpointStore()
+	syncer := NewMessageSyncer(client, store, "warehouse")
+	ctx := context.Background()
+
+	messages, err := syncer.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2"}, syncedIDs(messages))
+	assert.Equal(t, "limit=20", server.queries[0])
+
+	checkpoint, _ := store.Load(ctx, "warehouse")
+	assert.Equal(t, "2021-03-01T12:01:00Z", checkpoint.From.Format(time.RFC3339))
+	assert.Equal(t, []string{"m2"}, checkpoint.SeenIDs)
+
+	// m2 is listed again, because it is at the checkpoint.
+	messages, err = syncer.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+	assert.Equal(t, "from=2021-03-01T12%3A01%3A00Z&limit=20", server.queries[1])
+
+	server.set("m3", "2021-03-01T12:01:00Z", "2021-03-01T12:01:00Z")
+	server.set("m4", "2021-03-01T12:02:00Z", "2021-03-01T12:02:00Z")
+
+	// Updates of messages created before the checkpoint are not listed.
+	server.set("m1", "2021-03-01T12:00:00Z", "2021-03-01T12:03:00Z")
+
+	messages, err = syncer.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m3", "m4"}, syncedIDs(messages))
+
+	checkpoint, _ = store.Load(ctx, "warehouse")
+	assert.Equal(t, "2021-03-01T12:02:00Z", checkpoint.From.Format(time.RFC3339))
+	assert.Equal(t, []string{"m4"}, checkpoint.SeenIDs)
+}
+
+func TestMessageSyncerUpdateWindow(t *testing.T) {
+	server := &syncServer{messages: make(map[string]*Message)}
+	server.set("m0", "2021-03-01T11:54:00Z", "2021-03-01T11:54:00Z")
+	server.set("m1", "2021-03-01T12:00:00Z", "2021-03-01T12:00:00Z")
+	server.set("m2", "2021-03-01T12:01:00Z", "2021-03-01T12:05:00Z")
+
+	transport, teardown := mbtest.HTTPTestTransport(server)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	store := NewMemoryCheckpointStore()
+	syncer := NewMessageSyncer(client, store, "warehouse")
+	syncer.Start = time.Date(2021, 3, 1, 11, 55, 0, 0, time.UTC)
+	syncer.UpdateWindow = 10 * time.Minute
+	ctx := context.Background()
+
+	messages, err := syncer.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2"}, syncedIDs(messages))
+
+	checkpoint, _ := store.Load(ctx, "warehouse")
+	assert.Equal(t, "2021-03-01T12:05:00Z", checkpoint.Updated.Format(time.RFC3339))
+	assert.Equal(t, []string{"m2"}, checkpoint.UpdatedIDs)
+
+	// Messages in the window that didn't change are not returned again.
+	messages, err = syncer.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+	assert.Equal(t, "from=2021-03-01T11%3A51%3A00Z&limit=20", server.queries[1])
+
+	server.set("m1", "2021-03-01T12:00:00Z", "2021-03-01T12:06:00Z")
+	server.set("m3", "2021-03-01T12:02:00Z", "2021-03-01T12:06:00Z")
+
+	// m0 is before the Start, so its update is not returned.
+	server.set("m0", "2021-03-01T11:54:00Z", "2021-03-01T12:06:00Z")
+
+	messages, err = syncer.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m3"}, syncedIDs(messages))
+
+	checkpoint, _ = store.Load(ctx, "warehouse")
+	assert.Equal(t, "2021-03-01T12:02:00Z", checkpoint.From.Format(time.RFC3339))
+	assert.Equal(t, "2021-03-01T12:06:00Z", checkpoint.Updated.Format(time.RFC3339))
+	assert.Equal(t, []string{"m1", "m3"}, checkpoint.UpdatedIDs)
+
+	messages, err = syncer.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestMessageSyncerStart(t *testing.T) {
+	server := &syncServer{messages: make(map[string]*Message)}
+	server.set("m1", "2021-03-01T12:00:00Z", "2021-03-01T12:00:00Z")
+	server.set("m2", "2021-03-01T12:01:00Z", "2021-03-01T12:05:00Z")
+
+	transport, teardown := mbtest.HTTPTestTransport(server)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	syncer := NewMessageSyncer(client, NewMemoryCheckpointStore(), "warehouse")
+	syncer.Start = time.Date(2021, 3, 1, 12, 0, 30, 0, time.UTC)
+
+	messages, err := syncer.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2"}, syncedIDs(messages))
+}
+
+func TestNextCheckpoint(t *testing.T) {
+	at := func(id, created string) *Message {
+		createdAt, _ := time.Parse(time.RFC3339, created)
+		return &Message{ID: id, CreatedDatetime: &createdAt}
+	}
+
+	from, _ := time.Parse(time.RFC3339, "2021-03-01T12:01:00Z")
+	checkpoint := nextCheckpoint(
+		&SyncCheckpoint{From: from, SeenIDs: []string{"m1"}},
+		[]*Message{at("m2", "2021-03-01T12:01:00Z")},
+	)
+	assert.Equal(t, from, checkpoint.From)
+	assert.Equal(t, []string{"m1", "m2"}, checkpoint.SeenIDs)
+}
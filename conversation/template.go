@@ -0,0 +1,179 @@
+package conversation
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// WhatsAppTemplateCategory is the category WhatsApp approves a template for.
+type WhatsAppTemplateCategory string
+
+const (
+	WhatsAppTemplateCategoryMarketing      WhatsAppTemplateCategory = "MARKETING"
+	WhatsAppTemplateCategoryUtility        WhatsAppTemplateCategory = "UTILITY"
+	WhatsAppTemplateCategoryAuthentication WhatsAppTemplateCategory = "AUTHENTICATION"
+)
+
+// WhatsAppTemplateStatus indicates whether a template can be sent.
+type WhatsAppTemplateStatus string
+
+const (
+	WhatsAppTemplateStatusNew      WhatsAppTemplateStatus = "NEW"
+	WhatsAppTemplateStatusPending  WhatsAppTemplateStatus = "PENDING"
+	WhatsAppTemplateStatusApproved WhatsAppTemplateStatus = "APPROVED"
+	WhatsAppTemplateStatusRejected WhatsAppTemplateStatus = "REJECTED"
+)
+
+// WhatsAppTemplateComponentType is the part of a template a component
+// describes.
+type WhatsAppTemplateComponentType string
+
+const (
+	WhatsAppTemplateComponentHeader  WhatsAppTemplateComponentType = "HEADER"
+	WhatsAppTemplateComponentBody    WhatsAppTemplateComponentType = "BODY"
+	WhatsAppTemplateComponentFooter  WhatsAppTemplateComponentType = "FOOTER"
+	WhatsAppTemplateComponentButtons WhatsAppTemplateComponentType = "BUTTONS"
+)
+
+// WhatsAppTemplateHeaderFormat is the kind of content of a header component.
+type WhatsAppTemplateHeaderFormat string
+
+const (
+	WhatsAppTemplateHeaderText     WhatsAppTemplateHeaderFormat = "TEXT"
+	WhatsAppTemplateHeaderImage    WhatsAppTemplateHeaderFormat = "IMAGE"
+	WhatsAppTemplateHeaderVideo    WhatsAppTemplateHeaderFormat = "VIDEO"
+	WhatsAppTemplateHeaderDocument WhatsAppTemplateHeaderFormat = "DOCUMENT"
+)
+
+// WhatsAppTemplateButtonType is the action of a template button.
+type WhatsAppTemplateButtonType string
+
+const (
+	WhatsAppTemplateButtonQuickReply  WhatsAppTemplateButtonType = "QUICK_REPLY"
+	WhatsAppTemplateButtonURL         WhatsAppTemplateButtonType = "URL"
+	WhatsAppTemplateButtonPhoneNumber WhatsAppTemplateButtonType = "PHONE_NUMBER"
+)
+
+// WhatsAppTemplate is a message template that WhatsApp approved, or has yet
+// to approve. It is sent as an HSM; see HSMBuilder.
+type WhatsAppTemplate struct {
+	Name           string                       `json:"name"`
+	Language       string                       `json:"language"`
+	Category       WhatsAppTemplateCategory     `json:"category"`
+	Components     []*WhatsAppTemplateComponent `json:"components"`
+	Status         WhatsAppTemplateStatus       `json:"status,omitempty"`
+	Namespace      string                       `json:"namespace,omitempty"`
+	WABAID         string                       `json:"wabaId,omitempty"`
+	RejectedReason string                       `json:"rejectedReason,omitempty"`
+	CreatedAt      *time.Time                   `json:"createdAt,omitempty"`
+	UpdatedAt      *time.Time                   `json:"updatedAt,omitempty"`
+}
+
+// WhatsAppTemplateComponent is a part of a template. Text can contain
+// placeholders like {{1}}.
+type WhatsAppTemplateComponent struct {
+	Type    WhatsAppTemplateComponentType `json:"type"`
+	Format  WhatsAppTemplateHeaderFormat  `json:"format,omitempty"`
+	Text    string                        `json:"text,omitempty"`
+	Buttons []*WhatsAppTemplateButton     `json:"buttons,omitempty"`
+}
+
+// WhatsAppTemplateButton is a button of a template. A URL can end with a
+// placeholder like {{1}}.
+type WhatsAppTemplateButton struct {
+	Type        WhatsAppTemplateButtonType `json:"type"`
+	Text        string                     `json:"text"`
+	URL         string                     `json:"url,omitempty"`
+	PhoneNumber string                     `json:"phone_number,omitempty"`
+}
+
+type WhatsAppTemplateList struct {
+	Offset     int
+	Limit      int
+	Count      int
+	TotalCount int
+	Items      []*WhatsAppTemplate
+}
+
+// placeholderPattern matches placeholders in templates, e.g. {{1}}.
+var placeholderPattern = regexp.MustCompile(`{{\s*(\d+)\s*}}`)
+
+// Component gets the template's first component of the given type, or nil.
+func (t *WhatsAppTemplate) Component(componentType WhatsAppTemplateComponentType) *WhatsAppTemplateComponent {
+	for _, component := range t.Components {
+		if component.Type == componentType {
+			return component
+		}
+	}
+
+	return nil
+}
+
+// Placeholders gets the number of placeholders in the component's text.
+func (c *WhatsAppTemplateComponent) Placeholders() int {
+	return countPlaceholders(c.Text)
+}
+
+// countPlaceholders gets the highest placeholder number in s. Placeholders
+// are numbered from 1, so this is the number of parameters s needs.
+func countPlaceholders(s string) int {
+	max := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+		if n, err := strconv.Atoi(match[1]); err == nil && n > max {
+			max = n
+		}
+	}
+
+	return max
+}
+
+// ListWhatsAppTemplates gets a collection of the account's WhatsApp
+// templates. Pagination can be set in options.
+func ListWhatsAppTemplates(c messagebird.Client, options *messagebird.PaginationRequest) (*WhatsAppTemplateList, error) {
+	templateList := &WhatsAppTemplateList{}
+	if err := integrationsRequest(c, templateList, http.MethodGet, whatsAppTemplatesPath+"?"+options.QueryParams(), nil); err != nil {
+		return nil, err
+	}
+
+	return templateList, nil
+}
+
+// ReadWhatsAppTemplate gets the template with the given name and language.
+func ReadWhatsAppTemplate(c messagebird.Client, name, language string) (*WhatsAppTemplate, error) {
+	template := &WhatsAppTemplate{}
+	if err := integrationsRequest(c, template, http.MethodGet, whatsAppTemplatePath(name, language), nil); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// CreateWhatsAppTemplate submits a new template to WhatsApp for approval.
+func CreateWhatsAppTemplate(c messagebird.Client, template *WhatsAppTemplate) (*WhatsAppTemplate, error) {
+	created := &WhatsAppTemplate{}
+	if err := integrationsRequest(c, created, http.MethodPost, whatsAppTemplatesPath, template); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// DeleteWhatsAppTemplate deletes the template with the given name and
+// language. If language is empty, the template is deleted in all languages.
+func DeleteWhatsAppTemplate(c messagebird.Client, name, language string) error {
+	return integrationsRequest(c, nil, http.MethodDelete, whatsAppTemplatePath(name, language), nil)
+}
+
+func whatsAppTemplatePath(name, language string) string {
+	path := whatsAppTemplatesPath + "/" + url.PathEscape(name)
+	if language != "" {
+		path += "/" + url.PathEscape(language)
+	}
+
+	return path
+}
@@ -0,0 +1,67 @@
+package conversation
+
+import (
+	"net/http"
+	"testing"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListWhatsAppTemplates(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "whatsAppTemplateListObject.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	templates, err := ListWhatsAppTemplates(client, &messagebird.PaginationRequest{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, templates.TotalCount)
+	assert.Equal(t, "welcome", templates.Items[0].Name)
+	assert.Equal(t, WhatsAppTemplateStatusApproved, templates.Items[0].Status)
+	assert.Equal(t, 1, templates.Items[0].Component(WhatsAppTemplateComponentBody).Placeholders())
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/v2/platforms/whatsapp/templates")
+	assert.Equal(t, "limit=10&offset=0", mbtest.Request.URL.RawQuery)
+}
+
+func TestReadWhatsAppTemplate(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "whatsAppTemplateObject.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	template, err := ReadWhatsAppTemplate(client, "order_shipped", "en")
+	assert.NoError(t, err)
+	assert.Equal(t, WhatsAppTemplateHeaderImage, template.Component(WhatsAppTemplateComponentHeader).Format)
+	assert.Equal(t, WhatsAppTemplateButtonURL, template.Component(WhatsAppTemplateComponentButtons).Buttons[0].Type)
+	assert.Nil(t, template.Component("CAROUSEL"))
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/v2/platforms/whatsapp/templates/order_shipped/en")
+}
+
+func TestCreateWhatsAppTemplate(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "whatsAppTemplateObject.json", http.StatusCreated)
+	client := mbtest.Client(t)
+
+	_, err := CreateWhatsAppTemplate(client, &WhatsAppTemplate{
+		Name:     "welcome",
+		Language: "en",
+		Category: WhatsAppTemplateCategoryMarketing,
+		Components: []*WhatsAppTemplateComponent{
+			{Type: WhatsAppTemplateComponentBody, Text: "Welcome, {{1}}!"},
+		},
+	})
+	assert.NoError(t, err)
+
+	mbtest.AssertEndpointCalled(t, http.MethodPost, "/v2/platforms/whatsapp/templates")
+	mbtest.AssertTestdataJson(t, "whatsAppTemplateCreateRequest.json", mbtest.Request.Body)
+}
+
+func TestDeleteWhatsAppTemplate(t *testing.T) {
+	mbtest.WillReturn([]byte(""), http.StatusNoContent)
+	client := mbtest.Client(t)
+
+	assert.NoError(t, DeleteWhatsAppTemplate(client, "welcome", "en"))
+	mbtest.AssertEndpointCalled(t, http.MethodDelete, "/v2/platforms/whatsapp/templates/welcome/en")
+
+	assert.NoError(t, DeleteWhatsAppTemplate(client, "welcome", ""))
+	mbtest.AssertEndpointCalled(t, http.MethodDelete, "/v2/platforms/whatsapp/templates/welcome")
+}
@@ -0,0 +1,437 @@
+package conversation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// TranscriptFormat is the format a transcript is written in.
+type TranscriptFormat string
+
+const (
+	// TranscriptJSONL writes every Message as a line of JSON.
+	TranscriptJSONL TranscriptFormat = "jsonl"
+
+	// TranscriptCSV writes a header and a row per message.
+	TranscriptCSV TranscriptFormat = "csv"
+
+	// TranscriptHTML writes a standalone HTML document.
+	TranscriptHTML TranscriptFormat = "html"
+
+	// TranscriptMarkdown writes a section per message.
+	TranscriptMarkdown TranscriptFormat = "markdown"
+)
+
+// DefaultTranscriptPageSize is the number of messages that are listed per
+// request by default.
+const DefaultTranscriptPageSize = 20
+
+// TranscriptOptions configure how a transcript is exported.
+type TranscriptOptions struct {
+	// Format defaults to TranscriptJSONL.
+	Format TranscriptFormat
+
+	// AfterMessageID resumes an export: only the messages after it are
+	// written. It is the ID that was returned by the previous export. The
+	// CSV header, HTML document and Markdown title are not written again, so
+	// the output can be appended to that of the previous export. For
+	// TranscriptHTML, it has to go in the body of the previous document.
+	//
+	// All messages are still listed, as the API can't list the messages
+	// after a given one.
+	AfterMessageID string
+
+	// PageSize defaults to DefaultTranscriptPageSize.
+	PageSize int
+}
+
+// transcriptColumns are the columns of TranscriptCSV.
+var transcriptColumns = []string{
+	"id", "conversationId", "createdDatetime", "updatedDatetime", "platform", "channelId",
+	"direction", "status", "from", "to", "type", "text", "mediaUrl",
+}
+
+// ExportConversationTranscript writes all messages of a conversation to w,
+// from old to new. It returns the ID of the last message that was written, so
+// a later export can continue after it. If no messages were written, the
+// AfterMessageID of options is returned.
+//
+// To write the messages in order of creation, whatever order the API lists
+// them in, all messages are listed and held in memory before the first one is
+// written. For very long
+// conversations, use ListConversationMessages to process them page by page.
+func ExportConversationTranscript(c messagebird.Client, w io.Writer, conversationID string, options *TranscriptOptions) (string, error) {
+	options = transcriptOptions(options)
+
+	messages, err := listAllConversationMessages(c, conversationID, options.PageSize)
+	if err != nil {
+		return "", err
+	}
+
+	return writeTranscript(w, "Conversation "+conversationID, messages, options)
+}
+
+// ExportContactTranscript writes all messages of all conversations of a
+// contact to w, from old to new. Its return values are the same as for
+// ExportConversationTranscript.
+//
+// Like for ExportConversationTranscript, all messages are held in memory, as
+// the messages of the conversations are written interleaved by time.
+func ExportContactTranscript(c messagebird.Client, w io.Writer, contactID string, options *TranscriptOptions) (string, error) {
+	options = transcriptOptions(options)
+
+	var messages []*Message
+	pagination := &messagebird.PaginationRequest{Limit: options.PageSize}
+	for {
+		conversations, err := ListByContact(c, contactID, pagination)
+		if err != nil {
+			return "", err
+		}
+
+		for _, conversationID := range conversations.Items {
+			if conversationID == nil {
+				continue
+			}
+			conversationMessages, err := listAllConversationMessages(c, *conversationID, options.PageSize)
+			if err != nil {
+				return "", err
+			}
+			messages = append(messages, conversationMessages...)
+		}
+
+		pagination.Offset += len(conversations.Items)
+		if len(conversations.Items) == 0 || pagination.Offset >= conversations.TotalCount {
+			break
+		}
+	}
+
+	return writeTranscript(w, "Contact "+contactID, messages, options)
+}
+
+func transcriptOptions(options *TranscriptOptions) *TranscriptOptions {
+	o := TranscriptOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.Format == "" {
+		o.Format = TranscriptJSONL
+	}
+	if o.PageSize <= 0 {
+		o.PageSize = DefaultTranscriptPageSize
+	}
+
+	return &o
+}
+
+func listAllConversationMessages(c messagebird.Client, conversationID string, pageSize int) ([]*Message, error) {
+	var messages []*Message
+
+	options := &ListConversationMessagesRequest{}
+	options.Limit = pageSize
+	for {
+		page, err := ListConversationMessages(c, conversationID, options)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, page.Items...)
+
+		options.Offset += len(page.Items)
+		if len(page.Items) == 0 || options.Offset >= page.TotalCount {
+			return messages, nil
+		}
+	}
+}
+
+func writeTranscript(w io.Writer, title string, messages []*Message, options *TranscriptOptions) (string, error) {
+	// Sort by creation, so the order doesn't depend on the order of pages
+	// and conversations. The ID breaks ties, so resuming is deterministic.
+	sort.SliceStable(messages, func(i, j int) bool {
+		ti, tj := messageTime(messages[i]), messageTime(messages[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+
+	if options.AfterMessageID != "" {
+		i := 0
+		for i < len(messages) && messages[i].ID != options.AfterMessageID {
+			i++
+		}
+		if i == len(messages) {
+			return "", fmt.Errorf("message %s to resume after was not found", options.AfterMessageID)
+		}
+		messages = messages[i+1:]
+	}
+
+	// A resumed export continues the output of the previous one.
+	header := options.AfterMessageID == ""
+
+	var err error
+	switch options.Format {
+	case TranscriptJSONL:
+		err = writeTranscriptJSONL(w, messages)
+	case TranscriptCSV:
+		err = writeTranscriptCSV(w, messages, header)
+	case TranscriptHTML:
+		err = writeTranscriptHTML(w, title, messages, header)
+	case TranscriptMarkdown:
+		err = writeTranscriptMarkdown(w, title, messages, header)
+	default:
+		return "", fmt.Errorf("unknown transcript format %s", options.Format)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(messages) == 0 {
+		return options.AfterMessageID, nil
+	}
+
+	return messages[len(messages)-1].ID, nil
+}
+
+func writeTranscriptJSONL(w io.Writer, messages []*Message) error {
+	enc := json.NewEncoder(w)
+	for _, message := range messages {
+		if err := enc.Encode(message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeTranscriptCSV(w io.Writer, messages []*Message, header bool) error {
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write(transcriptColumns); err != nil {
+			return err
+		}
+	}
+
+	for _, message := range messages {
+		record := []string{
+			message.ID,
+			message.ConversationID,
+			formatTranscriptTime(message.CreatedDatetime),
+			formatTranscriptTime(message.UpdatedDatetime),
+			message.Platform,
+			message.ChannelID,
+			string(message.Direction),
+			string(message.Status),
+			message.From,
+			string(message.To),
+			string(message.Type),
+			messageText(message.Content),
+			messageMediaURL(message.Content),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeTranscriptHTML(w io.Writer, title string, messages []*Message, document bool) error {
+	var b strings.Builder
+
+	if document {
+		b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+		fmt.Fprintf(&b, "<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n", html.EscapeString(title), html.EscapeString(title))
+	}
+
+	for _, message := range messages {
+		fmt.Fprintf(&b, "<div class=\"message %s\" id=\"%s\">\n", html.EscapeString(string(message.Direction)), html.EscapeString(message.ID))
+		fmt.Fprintf(&b, "<p class=\"meta\">%s</p>\n", html.EscapeString(messageMeta(message)))
+		if text := messageText(message.Content); text != "" {
+			fmt.Fprintf(&b, "<p>%s</p>\n", strings.ReplaceAll(html.EscapeString(text), "\n", "<br>"))
+		}
+		if url := messageMediaURL(message.Content); isWebURL(url) {
+			fmt.Fprintf(&b, "<p><a href=\"%s\">%s</a></p>\n", html.EscapeString(url), html.EscapeString(string(message.Type)))
+		} else if url != "" {
+			fmt.Fprintf(&b, "<p>%s: %s</p>\n", html.EscapeString(string(message.Type)), html.EscapeString(url))
+		}
+		b.WriteString("</div>\n")
+	}
+
+	if document {
+		b.WriteString("</body>\n</html>\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeTranscriptMarkdown(w io.Writer, title string, messages []*Message, header bool) error {
+	var b strings.Builder
+
+	if header {
+		fmt.Fprintf(&b, "# %s\n", escapeMarkdown(title))
+	}
+	for _, message := range messages {
+		fmt.Fprintf(&b, "\n**%s**\n", escapeMarkdown(messageMeta(message)))
+		if text := messageText(message.Content); text != "" {
+			fmt.Fprintf(&b, "\n%s\n", escapeMarkdown(text))
+		}
+		if url := messageMediaURL(message.Content); isWebURL(url) {
+			fmt.Fprintf(&b, "\n[%s](<%s>)\n", escapeMarkdown(string(message.Type)), markdownURLEscaper.Replace(url))
+		} else if url != "" {
+			fmt.Fprintf(&b, "\n%s: %s\n", escapeMarkdown(string(message.Type)), markdownCode(url))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscaper escapes the characters that start inline Markdown, like
+// emphasis, links and HTML, wherever they are in a line.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`,
+	"&", `\&`, "#", `\#`, "|", `\|`, "~", `\~`,
+)
+
+// markdownURLEscaper escapes the characters that end a link destination in
+// angle brackets.
+var markdownURLEscaper = strings.NewReplacer("<", "%3C", ">", "%3E", "\n", "%0A")
+
+// escapeMarkdown escapes s, so it is written as text rather than read as
+// Markdown. Besides the inline characters, the markers that start a list or
+// heading at the start of a line are escaped.
+func escapeMarkdown(s string) string {
+	lines := strings.Split(markdownEscaper.Replace(s), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(trimmed)]
+
+		digits := 0
+		for digits < len(trimmed) && trimmed[digits] >= '0' && trimmed[digits] <= '9' {
+			digits++
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "-"), strings.HasPrefix(trimmed, "+"), strings.HasPrefix(trimmed, "="):
+			lines[i] = indent + `\` + trimmed
+		case digits > 0 && digits < len(trimmed) && (trimmed[digits] == '.' || trimmed[digits] == ')'):
+			lines[i] = indent + trimmed[:digits] + `\` + trimmed[digits:]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// markdownCode writes s as a code span, delimited by more backticks than s
+// contains in a row.
+func markdownCode(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		if run++; run > longest {
+			longest = run
+		}
+	}
+
+	fence := strings.Repeat("`", longest+1)
+	if longest > 0 {
+		return fence + " " + s + " " + fence
+	}
+
+	return fence + s + fence
+}
+
+// messageMeta describes a message for readable transcripts, e.g.
+// "2021-03-01T12:00:00Z received on whatsapp from +31612345678 (read)".
+func messageMeta(message *Message) string {
+	meta := formatTranscriptTime(message.CreatedDatetime) + " " + string(message.Direction)
+	if message.Platform != "" {
+		meta += " on " + message.Platform
+	}
+	if message.From != "" {
+		meta += " from " + message.From
+	}
+	if message.Status != "" {
+		meta += " (" + string(message.Status) + ")"
+	}
+
+	return meta
+}
+
+// messageText gets the readable text of content, or a short description of
+// it if it has no text.
+func messageText(content *MessageContent) string {
+	if content == nil {
+		return ""
+	}
+
+	switch {
+	case content.Text != "":
+		return content.Text
+	case content.Image != nil:
+		return content.Image.Caption
+	case content.Video != nil:
+		return content.Video.Caption
+	case content.File != nil:
+		return content.File.Caption
+	case content.Location != nil:
+		return fmt.Sprintf("%f, %f", content.Location.Latitude, content.Location.Longitude)
+	case content.HSM != nil:
+		return "template " + content.HSM.TemplateName
+	case content.Interactive != nil && content.Interactive.Reply != nil:
+		return content.Interactive.Reply.Text
+	case content.Interactive != nil && content.Interactive.Body != nil:
+		return content.Interactive.Body.Text
+	case content.WhatsAppText != nil && content.WhatsAppText.Text != nil:
+		return content.WhatsAppText.Text.Body
+	case content.Email != nil:
+		return content.Email.Subject
+	}
+
+	return ""
+}
+
+func messageMediaURL(content *MessageContent) string {
+	if content == nil {
+		return ""
+	}
+
+	return content.MediaURL()
+}
+
+// isWebURL reports whether rawURL is an http or https URL, which transcripts
+// link to. Other schemes, like javascript:, are written as text.
+func isWebURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+func messageTime(message *Message) time.Time {
+	if message.CreatedDatetime == nil {
+		return time.Time{}
+	}
+
+	return *message.CreatedDatetime
+}
+
+func formatTranscriptTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,174 @@
+package conversation
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// transcriptHandler serves two conversations of a contact. The messages are
+// listed from new to old, one per page.
+var transcriptHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	pages := map[string][]string{
+		"/v1/conversations/conv1/messages": {
+			`{"id": "m3", "conversationId": "conv1", "platform": "whatsapp", "direction": "sent", "status": "read", "type": "image", "content": {"image": {"url": "https://example.com/a.jpg", "caption": "Your order"}}, "createdDatetime": "2021-03-01T12:02:00Z"}`,
+			`{"id": "m1", "conversationId": "conv1", "platform": "whatsapp", "direction": "received", "from": "+31612345678", "status": "received", "type": "text", "content": {"text": "Where is <my> order?"}, "createdDatetime": "2021-03-01T12:00:00Z"}`,
+		},
+		"/v1/conversations/conv2/messages": {
+			`{"id": "m2", "conversationId": "conv2", "platform": "sms", "direction": "sent", "status": "delivered", "type": "text", "content": {"text": "Hello, Jen"}, "createdDatetime": "2021-03-01T12:01:00Z"}`,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.URL.Path == "/v1/conversations/contact/contactid" {
+		w.Write([]byte(`{"offset": 0, "limit": 20, "count": 2, "totalCount": 2, "items": ["conv1", "conv2"]}`))
+		return
+	}
+
+	items, ok := pages[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors": [{"code": 404, "description": "not found"}]}`))
+		return
+	}
+
+	var page string
+	switch r.URL.Query().Get("offset") {
+	case "", "0":
+		page = items[0]
+	case "1":
+		if len(items) > 1 {
+			page = items[1]
+		}
+	}
+	w.Write([]byte(`{"offset": 0, "limit": 1, "count": 1, "totalCount": ` + strconv.Itoa(len(items)) + `, "items": [` + page + `]}`))
+})
+
+func TestExportConversationTranscript(t *testing.T) {
+	transport, teardown := mbtest.HTTPTestTransport(transcriptHandler)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	var buf bytes.Buffer
+	lastID, err := ExportConversationTranscript(client, &buf, "conv1", &TranscriptOptions{PageSize: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "m3", lastID)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"ID":"m1"`)
+	assert.Contains(t, lines[1], `"ID":"m3"`)
+
+	buf.Reset()
+	lastID, err = ExportConversationTranscript(client, &buf, "conv1", &TranscriptOptions{PageSize: 1, AfterMessageID: "m1", Format: TranscriptCSV})
+	assert.NoError(t, err)
+	assert.Equal(t, "m3", lastID)
+	// The header was written by the export that is resumed.
+	assert.Equal(t, "m3,conv1,2021-03-01T12:02:00Z,,whatsapp,,sent,read,,,image,Your order,https://example.com/a.jpg\n", buf.String())
+
+	buf.Reset()
+	lastID, err = ExportConversationTranscript(client, &buf, "conv1", &TranscriptOptions{PageSize: 1, AfterMessageID: "m3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "m3", lastID)
+	assert.Empty(t, buf.String())
+
+	_, err = ExportConversationTranscript(client, &buf, "conv1", &TranscriptOptions{PageSize: 1, AfterMessageID: "unknown"})
+	assert.EqualError(t, err, "message unknown to resume after was not found")
+}
+
+func TestExportContactTranscript(t *testing.T) {
+	transport, teardown := mbtest.HTTPTestTransport(transcriptHandler)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	var buf bytes.Buffer
+	lastID, err := ExportContactTranscript(client, &buf, "contactid", &TranscriptOptions{PageSize: 1, Format: TranscriptMarkdown})
+	assert.NoError(t, err)
+	assert.Equal(t, "m3", lastID)
+	assert.Equal(t, `# Contact contactid
+
+**2021-03-01T12:00:00Z received on whatsapp from +31612345678 (received)**
+
+Where is \<my\> order?
+
+**2021-03-01T12:01:00Z sent on sms (delivered)**
+
+Hello, Jen
+
+**2021-03-01T12:02:00Z sent on whatsapp (read)**
+
+Your order
+
+[image](<https://example.com/a.jpg>)
+`, buf.String())
+
+	buf.Reset()
+	_, err = ExportContactTranscript(client, &buf, "contactid", &TranscriptOptions{PageSize: 1, Format: TranscriptHTML, AfterMessageID: "m2"})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), `<div class="message sent" id="m3">`))
+	assert.NotContains(t, buf.String(), "<html>")
+	assert.NotContains(t, buf.String(), "Where is")
+	assert.Contains(t, buf.String(), `<a href="https://example.com/a.jpg">image</a>`)
+
+	buf.Reset()
+	_, err = ExportContactTranscript(client, &buf, "contactid", &TranscriptOptions{PageSize: 1, Format: TranscriptHTML})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "<title>Contact contactid</title>")
+	assert.True(t, strings.HasSuffix(buf.String(), "</html>\n"))
+
+	_, err = ExportContactTranscript(client, &buf, "contactid", &TranscriptOptions{Format: "pdf"})
+	assert.EqualError(t, err, "unknown transcript format pdf")
+}
+
+func TestTranscriptMediaURLScheme(t *testing.T) {
+	_, content := ImageMessage("javascript:alert(1)", "")
+	messages := []*Message{{ID: "m1", Type: MessageTypeImage, Content: content}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeTranscriptHTML(&buf, "Conversation", messages, false))
+	assert.NotContains(t, buf.String(), "href")
+	assert.Contains(t, buf.String(), "<p>image: javascript:alert(1)</p>")
+
+	buf.Reset()
+	assert.NoError(t, writeTranscriptMarkdown(&buf, "Conversation", messages, false))
+	assert.NotContains(t, buf.String(), "](")
+	assert.Contains(t, buf.String(), "image: `javascript:alert(1)`")
+}
+
+func TestTranscriptMarkdownEscape(t *testing.T) {
+	messages := []*Message{{
+		ID:        "m1",
+		Type:      MessageTypeText,
+		Direction: MessageDirectionReceived,
+		From:      "**[x](javascript:alert(1))**",
+		Content:   &MessageContent{Text: "# Heading\n- item\n1. item\n<script>alert(1)</script> _a_ `b` ![c](d)"},
+	}, {
+		ID:      "m2",
+		Type:    MessageTypeImage,
+		Content: &MessageContent{Image: &Image{URL: "https://example.com/a>b.jpg"}},
+	}, {
+		ID:      "m3",
+		Type:    MessageTypeFile,
+		Content: &MessageContent{File: &File{URL: "ftp://example.com/`a`"}},
+	}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeTranscriptMarkdown(&buf, "Conversation *1*", messages, true))
+	assert.Equal(t, "# Conversation \\*1\\*\n"+
+		"\n** received from \\*\\*\\[x\\](javascript:alert(1))\\*\\***\n"+
+		"\n\\# Heading\n\\- item\n1\\. item\n\\<script\\>alert(1)\\</script\\> \\_a\\_ \\`b\\` !\\[c\\](d)\n"+
+		"\n** **\n"+
+		"\n[image](<https://example.com/a%3Eb.jpg>)\n"+
+		"\n** **\n"+
+		"\nfile: `` ftp://example.com/`a` ``\n", buf.String())
+}
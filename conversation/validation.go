@@ -0,0 +1,279 @@
+package conversation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// platformLimits describes the content a platform accepts. Zero lengths are
+// not limited.
+type platformLimits struct {
+	types            []MessageType
+	maxTextLength    int
+	maxCaptionLength int
+	maxQuickReplies  int
+	maxElements      int
+}
+
+// limits holds the limits of the platforms content is validated for. Other
+// platforms are only validated for consistency.
+var limits = map[Platform]*platformLimits{
+	PlatformWhatsApp: {
+		types: []MessageType{
+			MessageTypeText, MessageTypeImage, MessageTypeVideo, MessageTypeAudio,
+			MessageTypeFile, MessageTypeLocation, MessageTypeHSM, MessageTypeInteractive,
+			MessageTypeWhatsAppSticker, MessageTypeWhatsappText,
+		},
+		maxTextLength:    4096,
+		maxCaptionLength: 1024,
+	},
+	PlatformFacebook: {
+		types: []MessageType{
+			MessageTypeText, MessageTypeImage, MessageTypeVideo, MessageTypeAudio,
+			MessageTypeFile, MessageTypeFacebookQuickReply, MessageTypeFacebookMediaTemplate,
+			MessageTypeFacebookGenericTemplate, MessageTypeFacebookButtonTemplate,
+			MessageTypeFacebookReceiptTemplate,
+		},
+		maxTextLength:   2000,
+		maxQuickReplies: 13,
+		maxElements:     10,
+	},
+	PlatformEmail: {
+		types: []MessageType{MessageTypeEmail},
+	},
+	PlatformSMS: {
+		types: []MessageType{MessageTypeText},
+
+		// This is the length of 9 concatenated GSM-7 parts, the maximum the
+		// API sends.
+		maxTextLength: 1377,
+	},
+}
+
+// ValidateContent checks that content has exactly one field set, that it
+// matches messageType, and that it is within the limits of the platform.
+// Platform limits are checked for PlatformWhatsApp, PlatformFacebook,
+// PlatformEmail and PlatformSMS; other platforms only check consistency.
+func ValidateContent(platform Platform, messageType MessageType, content *MessageContent) error {
+	contentType, err := content.Type()
+	if err != nil {
+		return err
+	}
+	if messageType != contentType {
+		return fmt.Errorf("type is %s, but content is %s", messageType, contentType)
+	}
+
+	l, ok := limits[platform]
+	if !ok {
+		return nil
+	}
+
+	if !containsMessageType(l.types, messageType) {
+		return fmt.Errorf("%s: type %s is not supported", platform, messageType)
+	}
+
+	if err := l.validate(content); err != nil {
+		return fmt.Errorf("%s: %v", platform, err)
+	}
+
+	return nil
+}
+
+// Validate checks the content of the request with ValidateContent before
+// calling SendMessage.
+func (r *SendMessageRequest) Validate(platform Platform) error {
+	return ValidateContent(platform, r.Type, r.Content)
+}
+
+// Validate checks the content of the request with ValidateContent before
+// calling Reply.
+func (r *ReplyRequest) Validate(platform Platform) error {
+	return ValidateContent(platform, r.Type, r.Content)
+}
+
+// ValidateFor checks the request with Validate for the platform conv was last
+// used on, before calling Reply at the given time. On PlatformFacebook it also
+// checks the reply is within the messaging window with
+// CheckFacebookMessagingWindow: a Tag is required to reply outside of it.
+func (r *ReplyRequest) ValidateFor(conv *Conversation, at time.Time) error {
+	if err := r.Validate(conv.LastUsedPlatformID); err != nil {
+		return err
+	}
+
+	if conv.LastUsedPlatformID == PlatformFacebook {
+		return CheckFacebookMessagingWindow(conv.LastReceivedDatetime, r.Tag, at)
+	}
+
+	return nil
+}
+
+// Validate checks the content of the request with ValidateContent before
+// calling Start.
+func (r *StartRequest) Validate(platform Platform) error {
+	return ValidateContent(platform, r.Type, r.Content)
+}
+
+func (l *platformLimits) validate(content *MessageContent) error {
+	if err := maxLength("text", content.Text, l.maxTextLength); err != nil {
+		return err
+	}
+
+	for _, media := range []*Media{(*Media)(content.Image), (*Media)(content.Video), (*Media)(content.File)} {
+		if media == nil {
+			continue
+		}
+		if media.URL == "" {
+			return errors.New("media url is required")
+		}
+		if err := maxLength("caption", media.Caption, l.maxCaptionLength); err != nil {
+			return err
+		}
+	}
+	if content.Audio != nil && content.Audio.URL == "" {
+		return errors.New("media url is required")
+	}
+
+	if content.Interactive != nil && content.Interactive.Body != nil {
+		if err := maxLength("interactive body", content.Interactive.Body.Text, l.maxCaptionLength); err != nil {
+			return err
+		}
+	}
+
+	facebookMessages := []*FacebookMessage{
+		content.FacebookQuickReply, content.FacebookMediaTemplate, content.FacebookGenericTemplate,
+		content.FacebookButtonTemplate, content.FacebookReceiptTemplate,
+	}
+	for _, message := range facebookMessages {
+		if message == nil {
+			continue
+		}
+		if err := maxLength("text", message.Text, l.maxTextLength); err != nil {
+			return err
+		}
+		if l.maxQuickReplies > 0 && len(message.QuickReplies) > l.maxQuickReplies {
+			return fmt.Errorf("at most %d quick replies are allowed", l.maxQuickReplies)
+		}
+		if message.Attachment != nil && message.Attachment.Payload != nil && l.maxElements > 0 && len(message.Attachment.Payload.Elements) > l.maxElements {
+			return fmt.Errorf("at most %d template elements are allowed", l.maxElements)
+		}
+		if err := validateFacebookTemplate(message); err != nil {
+			return err
+		}
+	}
+
+	if content.Email != nil {
+		return validateEmail(content.Email)
+	}
+
+	return nil
+}
+
+func validateEmail(email *Email) error {
+	switch {
+	case len(email.To) == 0:
+		return errors.New("email needs at least one recipient")
+	case email.From == nil || email.From.Address == "":
+		return errors.New("email from address is required")
+	case email.Subject == "":
+		return errors.New("email subject is required")
+	case email.Content == nil || (email.Content.Html == "" && email.Content.Text == ""):
+		return errors.New("email needs html or text content")
+	}
+
+	for _, to := range email.To {
+		if to == nil || to.Address == "" {
+			return errors.New("email recipient address is required")
+		}
+	}
+
+	return nil
+}
+
+// maxFacebookButtons and maxFacebookButtonTextLength are the limits of the
+// button template.
+const (
+	maxFacebookButtons          = 3
+	maxFacebookButtonTextLength = 640
+)
+
+func validateFacebookTemplate(message *FacebookMessage) error {
+	if message.Attachment == nil || message.Attachment.Payload == nil {
+		return nil
+	}
+
+	payload := message.Attachment.Payload
+	switch payload.TemplateType {
+	case FBTemplateTypeButton:
+		if payload.Text == "" {
+			return errors.New("button template text is required")
+		}
+		if err := maxLength("button template text", payload.Text, maxFacebookButtonTextLength); err != nil {
+			return err
+		}
+		if len(payload.Buttons) == 0 || len(payload.Buttons) > maxFacebookButtons {
+			return fmt.Errorf("button template needs 1 to %d buttons, has %d", maxFacebookButtons, len(payload.Buttons))
+		}
+	case FBTemplateTypeReceipt:
+		switch {
+		case payload.RecipientName == "":
+			return errors.New("receipt recipient name is required")
+		case payload.OrderNumber == "":
+			return errors.New("receipt order number is required")
+		case payload.Currency == "":
+			return errors.New("receipt currency is required")
+		case payload.PaymentMethod == "":
+			return errors.New("receipt payment method is required")
+		case payload.Summary == nil:
+			return errors.New("receipt summary is required")
+		}
+	}
+
+	for _, button := range payload.Buttons {
+		if err := validateFacebookButton(button); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateFacebookButton(button *FacebookButton) error {
+	if button.Title == "" {
+		return errors.New("button title is required")
+	}
+
+	switch button.Type {
+	case FBButtonTypeWebUrl:
+		if button.Url == "" {
+			return fmt.Errorf("button %q needs a url", button.Title)
+		}
+	case FBButtonTypePostback, FBButtonTypePhoneNumber:
+		if button.Payload == "" {
+			return fmt.Errorf("button %q needs a payload", button.Title)
+		}
+	default:
+		return fmt.Errorf("button %q has unknown type %q", button.Title, button.Type)
+	}
+
+	return nil
+}
+
+func maxLength(name, s string, max int) error {
+	if max > 0 && utf8.RuneCountInString(s) > max {
+		return fmt.Errorf("%s exceeds %d characters", name, max)
+	}
+
+	return nil
+}
+
+func containsMessageType(types []MessageType, messageType MessageType) bool {
+	for _, t := range types {
+		if t == messageType {
+			return true
+		}
+	}
+
+	return false
+}
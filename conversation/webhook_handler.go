@@ -0,0 +1,197 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/messagebird/go-rest-api/v9/signature_jwt"
+)
+
+// ConversationEvent is the payload of the conversation.created and
+// conversation.updated webhook events.
+type ConversationEvent struct {
+	Type         WebhookEvent
+	Contact      *Contact
+	Conversation *Conversation
+}
+
+// MessageEvent is the payload of the message.created and message.updated
+// webhook events.
+type MessageEvent struct {
+	Type         WebhookEvent
+	Contact      *Contact
+	Conversation *Conversation
+	Message      *Message
+}
+
+// ConversationEventFunc handles a ConversationEvent.
+type ConversationEventFunc func(ctx context.Context, event *ConversationEvent) error
+
+// MessageEventFunc handles a MessageEvent.
+type MessageEventFunc func(ctx context.Context, event *MessageEvent) error
+
+// webhookPayload is the body of a webhook request, for any event.
+type webhookPayload struct {
+	Type         WebhookEvent
+	Contact      *Contact
+	Conversation *Conversation
+	Message      *Message
+}
+
+// WebhookHandler is an http.Handler that receives webhook requests for the
+// events registered with CreateWebhook. It verifies the signature of
+// requests, decodes their payload and dispatches it to the callback for the
+// event:
+//
+//	handler := conversation.NewWebhookHandler(signature_jwt.NewValidator("your signing key"), "https://yourdomain.com")
+//	handler.OnMessageCreated(func(ctx context.Context, event *conversation.MessageEvent) error {
+//		// handle event.Message
+//		return nil
+//	})
+//	http.Handle("/webhooks", handler)
+//
+// Requests with an invalid signature are rejected with 401 Unauthorized, and
+// requests that can't be decoded with 400 Bad Request. If a callback returns
+// an error, 500 Internal Server Error is returned so the request is retried.
+// Events without a callback are acknowledged with 200 OK.
+type WebhookHandler struct {
+	validator *signature_jwt.Validator
+	baseURL   string
+
+	mu                   sync.RWMutex
+	conversationHandlers map[WebhookEvent]ConversationEventFunc
+	messageHandlers      map[WebhookEvent]MessageEventFunc
+	messageTypeHandlers  map[MessageType]MessageEventFunc
+}
+
+// NewWebhookHandler creates a WebhookHandler that verifies requests with
+// validator. The baseURL is used to verify the URL of requests, see
+// signature_jwt.Validator.ValidateRequest. If validator is nil, requests are
+// not verified: only do this when they are verified before reaching the
+// handler.
+func NewWebhookHandler(validator *signature_jwt.Validator, baseURL string) *WebhookHandler {
+	return &WebhookHandler{
+		validator:            validator,
+		baseURL:              baseURL,
+		conversationHandlers: make(map[WebhookEvent]ConversationEventFunc),
+		messageHandlers:      make(map[WebhookEvent]MessageEventFunc),
+		messageTypeHandlers:  make(map[MessageType]MessageEventFunc),
+	}
+}
+
+// OnConversationCreated sets the callback for conversation.created events.
+func (h *WebhookHandler) OnConversationCreated(fn ConversationEventFunc) {
+	h.setConversationHandler(WebhookEventConversationCreated, fn)
+}
+
+// OnConversationUpdated sets the callback for conversation.updated events.
+func (h *WebhookHandler) OnConversationUpdated(fn ConversationEventFunc) {
+	h.setConversationHandler(WebhookEventConversationUpdated, fn)
+}
+
+// OnMessageCreated sets the callback for message.created events. It is not
+// called for messages with a type that has a callback set with
+// OnMessageType.
+func (h *WebhookHandler) OnMessageCreated(fn MessageEventFunc) {
+	h.setMessageHandler(WebhookEventMessageCreated, fn)
+}
+
+// OnMessageUpdated sets the callback for message.updated events, which are
+// sent when e.g. the status of a message changes.
+func (h *WebhookHandler) OnMessageUpdated(fn MessageEventFunc) {
+	h.setMessageHandler(WebhookEventMessageUpdated, fn)
+}
+
+// OnMessageType sets the callback for message.created events of messages with
+// the given type, e.g. MessageTypeImage. It takes precedence over the
+// callback set with OnMessageCreated.
+func (h *WebhookHandler) OnMessageType(messageType MessageType, fn MessageEventFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.messageTypeHandlers[messageType] = fn
+}
+
+func (h *WebhookHandler) setConversationHandler(event WebhookEvent, fn ConversationEventFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.conversationHandlers[event] = fn
+}
+
+func (h *WebhookHandler) setMessageHandler(event WebhookEvent, fn MessageEventFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.messageHandlers[event] = fn
+}
+
+// ServeHTTP implements http.Handler.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.validator != nil {
+		if err := h.validator.ValidateRequest(r, h.baseURL); err != nil {
+			http.Error(w, "", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	payload := &webhookPayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), payload); err != nil {
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// dispatch calls the callback for the payload's event, if any.
+func (h *WebhookHandler) dispatch(ctx context.Context, payload *webhookPayload) error {
+	h.mu.RLock()
+	conversationHandler := h.conversationHandlers[payload.Type]
+	messageHandler := h.messageHandlers[payload.Type]
+	if payload.Type == WebhookEventMessageCreated && payload.Message != nil {
+		if fn, ok := h.messageTypeHandlers[payload.Message.Type]; ok {
+			messageHandler = fn
+		}
+	}
+	h.mu.RUnlock()
+
+	switch payload.Type {
+	case WebhookEventConversationCreated, WebhookEventConversationUpdated:
+		if conversationHandler == nil {
+			return nil
+		}
+
+		return conversationHandler(ctx, &ConversationEvent{
+			Type:         payload.Type,
+			Contact:      payload.Contact,
+			Conversation: payload.Conversation,
+		})
+	case WebhookEventMessageCreated, WebhookEventMessageUpdated:
+		if messageHandler == nil {
+			return nil
+		}
+
+		return messageHandler(ctx, &MessageEvent{
+			Type:         payload.Type,
+			Contact:      payload.Contact,
+			Conversation: payload.Conversation,
+			Message:      payload.Message,
+		})
+	default:
+		return nil
+	}
+}
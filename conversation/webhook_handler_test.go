@@ -0,0 +1,161 @@
+package conversation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/messagebird/go-rest-api/v9/signature_jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testSigningKey = "hunter2"
+	testBaseURL    = "https://example.com"
+)
+
+// signedWebhookRequest creates a webhook request for payload, signed with key.
+func signedWebhookRequest(t *testing.T, key string, payload []byte) *http.Request {
+	urlHash := sha256.Sum256([]byte(testBaseURL + "/webhooks"))
+	payloadHash := sha256.Sum256(payload)
+
+	signature, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":          "MessageBird",
+		"nbf":          time.Now().Unix(),
+		"exp":          time.Now().Add(time.Minute).Unix(),
+		"jti":          "jtid",
+		"url_hash":     hex.EncodeToString(urlHash[:]),
+		"payload_hash": hex.EncodeToString(payloadHash[:]),
+	}).SignedString([]byte(key))
+	assert.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(payload))
+	r.Header.Set("MessageBird-Signature-JWT", signature)
+
+	return r
+}
+
+func newTestWebhookHandler() *WebhookHandler {
+	return NewWebhookHandler(signature_jwt.NewValidator(testSigningKey), testBaseURL)
+}
+
+func TestWebhookHandlerMessageCreated(t *testing.T) {
+	handler := newTestWebhookHandler()
+
+	var event *MessageEvent
+	handler.OnMessageCreated(func(ctx context.Context, e *MessageEvent) error {
+		event = e
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, signedWebhookRequest(t, testSigningKey, mbtest.Testdata(t, "webhookMessageCreatedPayload.json")))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.NotNil(t, event) {
+		assert.Equal(t, WebhookEventMessageCreated, event.Type)
+		assert.Equal(t, "31612345678", event.Contact.MSISDN)
+		assert.Equal(t, "convid", event.Conversation.ID)
+		assert.Equal(t, ConversationStatusActive, event.Conversation.Status)
+		assert.Equal(t, "mesid", event.Message.ID)
+		assert.Equal(t, MessageTypeImage, event.Message.Type)
+		assert.Equal(t, MessageDirectionReceived, event.Message.Direction)
+		assert.Equal(t, "https://media.messagebird.com/v1/media/medid", event.Message.Content.Image.URL)
+	}
+}
+
+func TestWebhookHandlerMessageType(t *testing.T) {
+	handler := newTestWebhookHandler()
+
+	var created, images int
+	handler.OnMessageCreated(func(ctx context.Context, e *MessageEvent) error {
+		created++
+		return nil
+	})
+	handler.OnMessageType(MessageTypeImage, func(ctx context.Context, e *MessageEvent) error {
+		images++
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, signedWebhookRequest(t, testSigningKey, mbtest.Testdata(t, "webhookMessageCreatedPayload.json")))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, created)
+	assert.Equal(t, 1, images)
+}
+
+func TestWebhookHandlerConversationCreated(t *testing.T) {
+	handler := newTestWebhookHandler()
+
+	var event *ConversationEvent
+	handler.OnConversationCreated(func(ctx context.Context, e *ConversationEvent) error {
+		event = e
+		return nil
+	})
+	handler.OnMessageCreated(func(ctx context.Context, e *MessageEvent) error {
+		t.Error("unexpected message event")
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, signedWebhookRequest(t, testSigningKey, mbtest.Testdata(t, "webhookConversationCreatedPayload.json")))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.NotNil(t, event) {
+		assert.Equal(t, WebhookEventConversationCreated, event.Type)
+		assert.Equal(t, "contid", event.Contact.ID)
+		assert.Equal(t, "chid", event.Conversation.LastUsedChannelID)
+	}
+}
+
+func TestWebhookHandlerUnhandledEvent(t *testing.T) {
+	handler := newTestWebhookHandler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, signedWebhookRequest(t, testSigningKey, mbtest.Testdata(t, "webhookMessageCreatedPayload.json")))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWebhookHandlerInvalidSignature(t *testing.T) {
+	handler := newTestWebhookHandler()
+	handler.OnMessageCreated(func(ctx context.Context, e *MessageEvent) error {
+		t.Error("unexpected message event")
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, signedWebhookRequest(t, "wrong key", mbtest.Testdata(t, "webhookMessageCreatedPayload.json")))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestWebhookHandlerInvalidPayload(t *testing.T) {
+	handler := newTestWebhookHandler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, signedWebhookRequest(t, testSigningKey, []byte("{")))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWebhookHandlerCallbackError(t *testing.T) {
+	handler := newTestWebhookHandler()
+	handler.OnMessageCreated(func(ctx context.Context, e *MessageEvent) error {
+		return errors.New("database unavailable")
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, signedWebhookRequest(t, testSigningKey, mbtest.Testdata(t, "webhookMessageCreatedPayload.json")))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,290 @@
+package conversation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/internal/reconcile"
+)
+
+// webhookSyncPageSize is the number of webhooks listed per request.
+const webhookSyncPageSize = 20
+
+// WebhookExtraPolicy is what PlanWebhooks does with webhooks that exist but
+// are not desired.
+type WebhookExtraPolicy string
+
+const (
+	// WebhookExtraKeep leaves extra webhooks alone.
+	WebhookExtraKeep WebhookExtraPolicy = "keep"
+
+	// WebhookExtraDisable disables extra webhooks, so they can be enabled
+	// again by hand.
+	WebhookExtraDisable WebhookExtraPolicy = "disable"
+
+	// WebhookExtraDelete deletes extra webhooks.
+	WebhookExtraDelete WebhookExtraPolicy = "delete"
+)
+
+// WebhookAction is a change PlanWebhooks plans for a webhook.
+type WebhookAction string
+
+const (
+	WebhookActionCreate  WebhookAction = "create"
+	WebhookActionUpdate  WebhookAction = "update"
+	WebhookActionDisable WebhookAction = "disable"
+	WebhookActionDelete  WebhookAction = "delete"
+)
+
+// WebhookSyncOptions configure PlanWebhooks.
+type WebhookSyncOptions struct {
+	// Extra defaults to WebhookExtraKeep.
+	Extra WebhookExtraPolicy
+
+	// Manage limits the webhooks that are disabled or deleted as extra ones,
+	// e.g. to those with a URL of this service when several services share
+	// an account. If it is nil, all webhooks are managed.
+	Manage func(webhook *Webhook) bool
+}
+
+// WebhookChange is a planned change of a single webhook.
+type WebhookChange struct {
+	Action WebhookAction
+
+	// Desired is nil when an extra webhook is disabled or deleted.
+	Desired *WebhookCreateRequest
+
+	// Existing is nil when a webhook is created.
+	Existing *Webhook
+
+	// Webhook is the created or updated webhook, after Apply.
+	Webhook *Webhook
+
+	// Err is the error of applying the change, after Apply.
+	Err error
+}
+
+func (c *WebhookChange) String() string {
+	webhook := c.Existing
+	if webhook == nil {
+		webhook = &Webhook{ChannelID: c.Desired.ChannelID, URL: c.Desired.URL}
+	}
+
+	s := string(c.Action) + " " + webhook.URL
+	if webhook.ChannelID != "" {
+		s += " (channel " + webhook.ChannelID + ")"
+	}
+	if c.Existing != nil {
+		s += " " + c.Existing.ID
+	}
+
+	return s
+}
+
+// WebhookPlan holds the changes that reconcile the webhooks of an account
+// with the desired ones. An empty plan means the webhooks are in sync.
+type WebhookPlan struct {
+	Changes []*WebhookChange
+}
+
+// String describes the plan with a line per change, e.g. to show it before
+// it is applied.
+func (p *WebhookPlan) String() string {
+	return reconcile.Describe(len(p.Changes), func(i int) string {
+		return p.Changes[i].String()
+	})
+}
+
+// PlanWebhooks compares the desired webhooks with the webhooks of the account
+// and plans the changes that are needed to reconcile them, without making
+// them. Webhooks are identified by their URL and ChannelID:
+//
+//   - desired webhooks that don't exist are created,
+//   - existing webhooks are updated if their events differ, if they are
+//     disabled, or if the desired Settings are set and differ. A changed
+//     password alone is not detected, because the API doesn't return it,
+//   - other webhooks are handled according to the Extra option.
+//
+// Call Apply on the plan to make the changes.
+func PlanWebhooks(c messagebird.Client, desired []*WebhookCreateRequest, options *WebhookSyncOptions) (*WebhookPlan, error) {
+	if options == nil {
+		options = &WebhookSyncOptions{}
+	}
+
+	desiredKeys := make([]string, len(desired))
+	declared := make(map[string]bool, len(desired))
+	for i, webhook := range desired {
+		if webhook.URL == "" {
+			return nil, fmt.Errorf("desired webhook %d has no url", i)
+		}
+		if len(webhook.Events) == 0 {
+			return nil, fmt.Errorf("desired webhook %s has no events", webhook.URL)
+		}
+
+		desiredKeys[i] = webhookKey(webhook.ChannelID, webhook.URL)
+		if declared[desiredKeys[i]] {
+			return nil, fmt.Errorf("desired webhook %s for channel %q is declared twice", webhook.URL, webhook.ChannelID)
+		}
+		declared[desiredKeys[i]] = true
+	}
+
+	existing, err := listAllWebhooks(c)
+	if err != nil {
+		return nil, err
+	}
+
+	existingKeys := make([]string, len(existing))
+	for i, webhook := range existing {
+		existingKeys[i] = webhookKey(webhook.ChannelID, webhook.URL)
+	}
+
+	inSync := func(d, e int) bool {
+		return webhookInSync(desired[d], existing[e])
+	}
+	extra := func(e int) reconcile.Action {
+		if options.Manage != nil && !options.Manage(existing[e]) {
+			return ""
+		}
+
+		switch options.Extra {
+		case WebhookExtraDisable:
+			if existing[e].Status != WebhookStatusDisabled {
+				return reconcile.Disable
+			}
+		case WebhookExtraDelete:
+			return reconcile.Delete
+		}
+
+		return ""
+	}
+
+	plan := &WebhookPlan{}
+	for _, step := range reconcile.Plan(desiredKeys, existingKeys, inSync, extra) {
+		change := &WebhookChange{Action: WebhookAction(step.Action)}
+		if step.Desired >= 0 {
+			change.Desired = desired[step.Desired]
+		}
+		if step.Existing >= 0 {
+			change.Existing = existing[step.Existing]
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	return plan, nil
+}
+
+// Apply makes the changes of the plan in order. A failed change doesn't stop
+// the others: its Err is set, and the first error is returned after all
+// changes were tried.
+func (p *WebhookPlan) Apply(c messagebird.Client) error {
+	return reconcile.Apply(len(p.Changes), func(i int) error {
+		change := p.Changes[i]
+		switch change.Action {
+		case WebhookActionCreate:
+			change.Webhook, change.Err = CreateWebhook(c, change.Desired)
+		case WebhookActionUpdate:
+			change.Webhook, change.Err = UpdateWebhook(c, change.Existing.ID, &WebhookUpdateRequest{
+				Events:   change.Desired.Events,
+				URL:      change.Desired.URL,
+				Status:   WebhookStatusEnabled,
+				Settings: change.Desired.Settings,
+			})
+		case WebhookActionDisable:
+			change.Webhook, change.Err = UpdateWebhook(c, change.Existing.ID, &WebhookUpdateRequest{
+				Status: WebhookStatusDisabled,
+			})
+		case WebhookActionDelete:
+			change.Err = DeleteWebhook(c, change.Existing.ID)
+		default:
+			change.Err = fmt.Errorf("unknown webhook action %s", change.Action)
+		}
+
+		return change.Err
+	})
+}
+
+func listAllWebhooks(c messagebird.Client) ([]*Webhook, error) {
+	var webhooks []*Webhook
+
+	pagination := &messagebird.PaginationRequest{Limit: webhookSyncPageSize}
+	for {
+		page, err := ListWebhooks(c, pagination)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, page.Items...)
+
+		pagination.Offset += len(page.Items)
+		if len(page.Items) == 0 || pagination.Offset >= page.TotalCount {
+			return webhooks, nil
+		}
+	}
+}
+
+func webhookKey(channelID, url string) string {
+	return channelID + " " + url
+}
+
+func webhookInSync(desired *WebhookCreateRequest, existing *Webhook) bool {
+	if existing.Status == WebhookStatusDisabled {
+		return false
+	}
+	if !sameWebhookEvents(desired.Events, existing.Events) {
+		return false
+	}
+	if desired.Settings != nil {
+		return existing.Settings != nil && sameWebhookSettings(desired.Settings, existing.Settings)
+	}
+
+	return true
+}
+
+// sameWebhookSettings compares the headers as JSON, because the values of
+// existing webhooks are decoded from JSON and e.g. an int header becomes a
+// float64. No headers and empty headers are the same. The password is not
+// compared, because the API doesn't return it.
+func sameWebhookSettings(a, b *WebhookSettings) bool {
+	if a.ExpectedHttpCode != b.ExpectedHttpCode || a.Username != b.Username ||
+		a.QueryParams != b.QueryParams || a.Retry != b.Retry || a.Timeout != b.Timeout {
+		return false
+	}
+	if len(a.Headers) == 0 || len(b.Headers) == 0 {
+		return len(a.Headers) == len(b.Headers)
+	}
+
+	aJSON, err := json.Marshal(a.Headers)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b.Headers)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(aJSON, bJSON)
+}
+
+// sameWebhookEvents reports whether a and b have the same events, in any
+// order.
+func sameWebhookEvents(a, b []WebhookEvent) bool {
+	count := make(map[WebhookEvent]int)
+	for _, event := range a {
+		count[event] = 1
+	}
+	for _, event := range b {
+		if count[event] == 0 {
+			return false
+		}
+		count[event] = 2
+	}
+	for _, n := range count {
+		if n != 2 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,148 @@
+package conversation
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// webhookServer serves a list of webhooks and records the changes made.
+type webhookServer struct {
+	webhooks []*Webhook
+	calls    []string
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var response interface{}
+	id := strings.TrimPrefix(r.URL.Path, "/v1/webhooks/")
+	switch r.Method {
+	case http.MethodGet:
+		response = &WebhookList{Items: s.webhooks, Count: len(s.webhooks), TotalCount: len(s.webhooks)}
+	case http.MethodPost:
+		req := &WebhookCreateRequest{}
+		decodeWebhookRequest(r, req)
+		s.calls = append(s.calls, "create "+req.URL)
+		response = &Webhook{ID: "new", URL: req.URL, ChannelID: req.ChannelID, Events: req.Events, Status: WebhookStatusEnabled}
+	case http.MethodPatch:
+		req := &WebhookUpdateRequest{}
+		decodeWebhookRequest(r, req)
+		s.calls = append(s.calls, "update "+id+" "+string(req.Status))
+		response = &Webhook{ID: id, URL: req.URL, Events: req.Events, Status: req.Status}
+	case http.MethodDelete:
+		s.calls = append(s.calls, "delete "+id)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		panic(err)
+	}
+}
+
+func decodeWebhookRequest(r *http.Request, req interface{}) {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		panic(err)
+	}
+}
+
+func TestPlanWebhooks(t *testing.T) {
+	server := &webhookServer{webhooks: []*Webhook{
+		{ID: "insync", ChannelID: "chid", URL: "https://example.com/a", Status: WebhookStatusEnabled,
+			Events: []WebhookEvent{WebhookEventMessageUpdated, WebhookEventMessageCreated}},
+		{ID: "drifted", URL: "https://example.com/b", Status: WebhookStatusEnabled,
+			Events: []WebhookEvent{WebhookEventMessageCreated}},
+		{ID: "disabled", URL: "https://example.com/c", Status: WebhookStatusDisabled,
+			Events: []WebhookEvent{WebhookEventMessageCreated}},
+		{ID: "extra", URL: "https://example.com/old", Status: WebhookStatusEnabled,
+			Events: []WebhookEvent{WebhookEventMessageCreated}},
+		{ID: "unmanaged", URL: "https://other.example.com/", Status: WebhookStatusEnabled,
+			Events: []WebhookEvent{WebhookEventMessageCreated}},
+	}}
+	transport, teardown := mbtest.HTTPTestTransport(server)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	desired := []*WebhookCreateRequest{
+		{ChannelID: "chid", URL: "https://example.com/a", Events: []WebhookEvent{WebhookEventMessageCreated, WebhookEventMessageUpdated}},
+		{URL: "https://example.com/b", Events: []WebhookEvent{WebhookEventMessageCreated, WebhookEventConversationCreated}},
+		{URL: "https://example.com/c", Events: []WebhookEvent{WebhookEventMessageCreated}},
+		{ChannelID: "other", URL: "https://example.com/a", Events: []WebhookEvent{WebhookEventMessageCreated}},
+	}
+	options := &WebhookSyncOptions{
+		Extra: WebhookExtraDisable,
+		Manage: func(webhook *Webhook) bool {
+			return strings.HasPrefix(webhook.URL, "https://example.com/")
+		},
+	}
+
+	plan, err := PlanWebhooks(client, desired, options)
+	assert.NoError(t, err)
+	assert.Equal(t, "update https://example.com/b drifted\n"+
+		"update https://example.com/c disabled\n"+
+		"create https://example.com/a (channel other)\n"+
+		"disable https://example.com/old extra", plan.String())
+	assert.Empty(t, server.calls)
+
+	assert.NoError(t, plan.Apply(client))
+	assert.Equal(t, []string{
+		"update drifted enabled",
+		"update disabled enabled",
+		"create https://example.com/a",
+		"update extra disabled",
+	}, server.calls)
+	assert.Equal(t, "new", plan.Changes[2].Webhook.ID)
+
+	options.Extra = WebhookExtraDelete
+	plan, err = PlanWebhooks(client, desired[:1], options)
+	assert.NoError(t, err)
+	assert.Equal(t, "delete https://example.com/b drifted\n"+
+		"delete https://example.com/c disabled\n"+
+		"delete https://example.com/old extra", plan.String())
+}
+
+func TestPlanWebhooksSettings(t *testing.T) {
+	settings := &WebhookSettings{Headers: map[string]interface{}{"X-Retries": 3}, Password: "secret", Timeout: 10}
+	server := &webhookServer{webhooks: []*Webhook{{
+		ID: "whid", URL: "https://example.com/", Status: WebhookStatusEnabled,
+		Events:   []WebhookEvent{WebhookEventMessageCreated},
+		Settings: &WebhookSettings{Headers: map[string]interface{}{"X-Retries": 3.0}, Timeout: 10},
+	}}}
+	transport, teardown := mbtest.HTTPTestTransport(server)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	desired := &WebhookCreateRequest{URL: "https://example.com/", Events: []WebhookEvent{WebhookEventMessageCreated}, Settings: settings}
+	// The password is not returned by the API, so it is not compared.
+	plan, err := PlanWebhooks(client, []*WebhookCreateRequest{desired}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Changes)
+
+	settings.Timeout = 20
+	plan, err = PlanWebhooks(client, []*WebhookCreateRequest{desired}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "update https://example.com/ whid", plan.String())
+}
+
+func TestPlanWebhooksInvalid(t *testing.T) {
+	client := mbtest.Client(t)
+
+	_, err := PlanWebhooks(client, []*WebhookCreateRequest{{URL: "https://example.com/"}}, nil)
+	assert.EqualError(t, err, "desired webhook https://example.com/ has no events")
+
+	events := []WebhookEvent{WebhookEventMessageCreated}
+	_, err = PlanWebhooks(client, []*WebhookCreateRequest{
+		{URL: "https://example.com/", Events: events},
+		{URL: "https://example.com/", Events: events},
+	}, nil)
+	assert.EqualError(t, err, `desired webhook https://example.com/ for channel "" is declared twice`)
+}
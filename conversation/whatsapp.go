@@ -1,5 +1,7 @@
 package conversation
 
+import "encoding/json"
+
 // WhatsAppInteractiveType
 // https://developers.messagebird.com/api/conversations/#whatsappinteractivetype-object
 type WhatsAppInteractiveType string
@@ -10,14 +12,15 @@ const (
 	WAITypeProduct     WhatsAppInteractiveType = "product"
 	WAITypeProductList WhatsAppInteractiveType = "product_list"
 	WAITypeButtonReply WhatsAppInteractiveType = "button_reply"
+	WAITypeListReply   WhatsAppInteractiveType = "list_reply"
 )
 
 // WhatsAppInteractive
 // https://developers.messagebird.com/api/conversations/#whatsappinteractive-object
 type WhatsAppInteractive struct {
 	Type   WhatsAppInteractiveType    `json:"type"`
-	Header *WhatsAppInteractiveHeader `json:"header"`
-	Body   *WhatsAppInteractiveBody   `json:"body"`
+	Header *WhatsAppInteractiveHeader `json:"header,omitempty"`
+	Body   *WhatsAppInteractiveBody   `json:"body,omitempty"`
 	Action *WhatsAppInteractiveAction `json:"action"`
 	Footer *WhatsAppInteractiveFooter `json:"footer,omitempty"`
 	Reply  *WhatsAppInteractiveReply  `json:"reply,omitempty"`
@@ -27,10 +30,10 @@ type WhatsAppInteractive struct {
 // https://developers.messagebird.com/api/conversations/#whatsappinteractiveheader-object
 type WhatsAppInteractiveHeader struct {
 	Type     WhatsAppInteractiveHeaderType `json:"type"`
-	Text     string                        `json:"text"`
-	Video    *Media                        `json:"video"`
-	Image    *Media                        `json:"image"`
-	Document *Media                        `json:"document"`
+	Text     string                        `json:"text,omitempty"`
+	Video    *Media                        `json:"video,omitempty"`
+	Image    *Media                        `json:"image,omitempty"`
+	Document *Media                        `json:"document,omitempty"`
 }
 
 // WhatsAppInteractiveHeaderType
@@ -53,19 +56,38 @@ type WhatsAppInteractiveBody struct {
 // WhatsAppInteractiveAction
 // https://developers.messagebird.com/api/conversations/#whatsappinteractiveaction-object
 type WhatsAppInteractiveAction struct {
-	CatalogId         string                        `json:"catalog_id"`
-	ProductRetailerId string                        `json:"product_retailer_id"`
-	Sections          []*WhatsAppInteractiveSection `json:"sections"`
-	Button            string                        `json:"button"`
-	Buttons           *WhatsAppInteractiveButton    `json:"buttons"`
+	CatalogId         string                        `json:"catalog_id,omitempty"`
+	ProductRetailerId string                        `json:"product_retailer_id,omitempty"`
+	Sections          []*WhatsAppInteractiveSection `json:"sections,omitempty"`
+	Button            string                        `json:"button,omitempty"`
+
+	// ReplyButtons are the buttons of a button message.
+	ReplyButtons []*WhatsAppInteractiveButton `json:"buttons,omitempty"`
+
+	// Deprecated: Buttons only holds a single button, use ReplyButtons
+	// instead. It is sent as the only button if ReplyButtons is empty.
+	Buttons *WhatsAppInteractiveButton `json:"-"`
+}
+
+// MarshalJSON is used to send the deprecated Buttons as a list, as the API
+// expects.
+func (a *WhatsAppInteractiveAction) MarshalJSON() ([]byte, error) {
+	type action WhatsAppInteractiveAction
+
+	target := action(*a)
+	if len(target.ReplyButtons) == 0 && target.Buttons != nil {
+		target.ReplyButtons = []*WhatsAppInteractiveButton{target.Buttons}
+	}
+
+	return json.Marshal(target)
 }
 
 // WhatsAppInteractiveSection
 // https://developers.messagebird.com/api/conversations/#whatsappinteractivesection-object
 type WhatsAppInteractiveSection struct {
-	Title        string                           `json:"title"`
-	Rows         []*WhatsAppInteractiveSectionRow `json:"rows"`
-	ProductItems []*WhatsAppInteractiveProduct    `json:"product_items"`
+	Title        string                           `json:"title,omitempty"`
+	Rows         []*WhatsAppInteractiveSectionRow `json:"rows,omitempty"`
+	ProductItems []*WhatsAppInteractiveProduct    `json:"product_items,omitempty"`
 }
 
 type WhatsAppInteractiveSectionRow struct {
@@ -0,0 +1,402 @@
+package conversation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Limits WhatsApp applies to interactive messages.
+const (
+	WhatsAppMaxReplyButtons         = 3
+	WhatsAppMaxListRows             = 10
+	WhatsAppMaxSections             = 10
+	WhatsAppMaxProducts             = 30
+	WhatsAppMaxButtonTitleLength    = 20
+	WhatsAppMaxRowTitleLength       = 24
+	WhatsAppMaxRowDescriptionLength = 72
+	WhatsAppMaxSectionTitleLength   = 24
+	WhatsAppMaxHeaderTextLength     = 60
+	WhatsAppMaxBodyTextLength       = 1024
+	WhatsAppMaxFooterTextLength     = 60
+)
+
+// whatsAppReplyButtonType is the type of buttons that send a reply.
+const whatsAppReplyButtonType = "reply"
+
+// WhatsAppInteractiveBuilder builds a WhatsAppInteractive and checks it
+// against the limits of WhatsApp when it is built. Create one with
+// NewWhatsAppButtons, NewWhatsAppList, NewWhatsAppProduct or
+// NewWhatsAppProductList:
+//
+//	interactive, err := conversation.NewWhatsAppButtons("Did this answer your question?").
+//		ReplyButton("yes", "Yes").
+//		ReplyButton("no", "No").
+//		Build()
+//	if err != nil {
+//		// handle error
+//	}
+//	req.SetContent(conversation.InteractiveMessage(interactive))
+type WhatsAppInteractiveBuilder struct {
+	interactive *WhatsAppInteractive
+}
+
+// NewWhatsAppButtons starts a message with up to 3 reply buttons.
+func NewWhatsAppButtons(body string) *WhatsAppInteractiveBuilder {
+	return newWhatsAppInteractiveBuilder(WAITypeButton, body, &WhatsAppInteractiveAction{})
+}
+
+// NewWhatsAppList starts a message with a list of up to 10 rows. The list is
+// opened with a button labeled button.
+func NewWhatsAppList(body, button string) *WhatsAppInteractiveBuilder {
+	return newWhatsAppInteractiveBuilder(WAITypeList, body, &WhatsAppInteractiveAction{Button: button})
+}
+
+// NewWhatsAppProduct starts a message with a single product from a catalog.
+// Its body is optional.
+func NewWhatsAppProduct(catalogID, productRetailerID, body string) *WhatsAppInteractiveBuilder {
+	return newWhatsAppInteractiveBuilder(WAITypeProduct, body, &WhatsAppInteractiveAction{
+		CatalogId:         catalogID,
+		ProductRetailerId: productRetailerID,
+	})
+}
+
+// NewWhatsAppProductList starts a message with up to 30 products from a
+// catalog. It needs a text header.
+func NewWhatsAppProductList(catalogID, header, body string) *WhatsAppInteractiveBuilder {
+	return newWhatsAppInteractiveBuilder(WAITypeProductList, body, &WhatsAppInteractiveAction{CatalogId: catalogID}).
+		HeaderText(header)
+}
+
+func newWhatsAppInteractiveBuilder(interactiveType WhatsAppInteractiveType, body string, action *WhatsAppInteractiveAction) *WhatsAppInteractiveBuilder {
+	interactive := &WhatsAppInteractive{Type: interactiveType, Action: action}
+	if body != "" {
+		interactive.Body = &WhatsAppInteractiveBody{Text: body}
+	}
+
+	return &WhatsAppInteractiveBuilder{interactive: interactive}
+}
+
+// HeaderText sets a text header.
+func (b *WhatsAppInteractiveBuilder) HeaderText(text string) *WhatsAppInteractiveBuilder {
+	b.interactive.Header = &WhatsAppInteractiveHeader{Type: WAIHeaderTypeText, Text: text}
+	return b
+}
+
+// HeaderImage sets an image header.
+func (b *WhatsAppInteractiveBuilder) HeaderImage(url string) *WhatsAppInteractiveBuilder {
+	b.interactive.Header = &WhatsAppInteractiveHeader{Type: WAIHeaderTypeImage, Image: &Media{URL: url}}
+	return b
+}
+
+// HeaderVideo sets a video header.
+func (b *WhatsAppInteractiveBuilder) HeaderVideo(url string) *WhatsAppInteractiveBuilder {
+	b.interactive.Header = &WhatsAppInteractiveHeader{Type: WAIHeaderTypeVideo, Video: &Media{URL: url}}
+	return b
+}
+
+// HeaderDocument sets a document header.
+func (b *WhatsAppInteractiveBuilder) HeaderDocument(url string) *WhatsAppInteractiveBuilder {
+	b.interactive.Header = &WhatsAppInteractiveHeader{Type: WAIHeaderTypeDocument, Document: &Media{URL: url}}
+	return b
+}
+
+// Footer sets the footer text.
+func (b *WhatsAppInteractiveBuilder) Footer(text string) *WhatsAppInteractiveBuilder {
+	b.interactive.Footer = &WhatsAppInteractiveFooter{Text: text}
+	return b
+}
+
+// ReplyButton adds a reply button. When it is tapped, the reply has its id.
+func (b *WhatsAppInteractiveBuilder) ReplyButton(id, title string) *WhatsAppInteractiveBuilder {
+	action := b.interactive.Action
+	action.ReplyButtons = append(action.ReplyButtons, &WhatsAppInteractiveButton{Id: id, Type: whatsAppReplyButtonType, Title: title})
+	return b
+}
+
+// Section starts a new section of a list or product list. Rows and products
+// are added to the last section. A title is required when there is more than
+// one section.
+func (b *WhatsAppInteractiveBuilder) Section(title string) *WhatsAppInteractiveBuilder {
+	action := b.interactive.Action
+	action.Sections = append(action.Sections, &WhatsAppInteractiveSection{Title: title})
+	return b
+}
+
+// Row adds a row to the current section of a list. When it is selected, the
+// reply has its id.
+func (b *WhatsAppInteractiveBuilder) Row(id, title, description string) *WhatsAppInteractiveBuilder {
+	section := b.currentSection()
+	section.Rows = append(section.Rows, &WhatsAppInteractiveSectionRow{Id: id, Title: title, Description: description})
+	return b
+}
+
+// Product adds a product to the current section of a product list.
+func (b *WhatsAppInteractiveBuilder) Product(productRetailerID string) *WhatsAppInteractiveBuilder {
+	section := b.currentSection()
+	section.ProductItems = append(section.ProductItems, &WhatsAppInteractiveProduct{ProductRetailerId: productRetailerID})
+	return b
+}
+
+func (b *WhatsAppInteractiveBuilder) currentSection() *WhatsAppInteractiveSection {
+	action := b.interactive.Action
+	if len(action.Sections) == 0 {
+		b.Section("")
+	}
+
+	return action.Sections[len(action.Sections)-1]
+}
+
+// Build checks the message against the limits of WhatsApp and returns a copy
+// of it, so the builder can be reused for a similar message.
+func (b *WhatsAppInteractiveBuilder) Build() (*WhatsAppInteractive, error) {
+	if err := b.validate(); err != nil {
+		return nil, fmt.Errorf("whatsapp %s: %v", b.interactive.Type, err)
+	}
+
+	return b.interactive.copy(), nil
+}
+
+// copy copies the message, so that changing the header, buttons or sections of
+// one does not change the other.
+func (i *WhatsAppInteractive) copy() *WhatsAppInteractive {
+	interactive := *i
+	if i.Header != nil {
+		header := *i.Header
+		header.Image, header.Video, header.Document = copyMedia(i.Header.Image), copyMedia(i.Header.Video), copyMedia(i.Header.Document)
+		interactive.Header = &header
+	}
+	if i.Body != nil {
+		body := *i.Body
+		interactive.Body = &body
+	}
+	if i.Footer != nil {
+		footer := *i.Footer
+		interactive.Footer = &footer
+	}
+	if i.Reply != nil {
+		reply := *i.Reply
+		interactive.Reply = &reply
+	}
+	if i.Action == nil {
+		return &interactive
+	}
+
+	action := *i.Action
+	if i.Action.Buttons != nil {
+		button := *i.Action.Buttons
+		action.Buttons = &button
+	}
+	action.ReplyButtons = nil
+	for _, button := range i.Action.ReplyButtons {
+		b := *button
+		action.ReplyButtons = append(action.ReplyButtons, &b)
+	}
+	action.Sections = nil
+	for _, section := range i.Action.Sections {
+		s := WhatsAppInteractiveSection{Title: section.Title}
+		for _, row := range section.Rows {
+			r := *row
+			s.Rows = append(s.Rows, &r)
+		}
+		for _, product := range section.ProductItems {
+			p := *product
+			s.ProductItems = append(s.ProductItems, &p)
+		}
+		action.Sections = append(action.Sections, &s)
+	}
+	interactive.Action = &action
+
+	return &interactive
+}
+
+func copyMedia(media *Media) *Media {
+	if media == nil {
+		return nil
+	}
+
+	m := *media
+	return &m
+}
+
+func (b *WhatsAppInteractiveBuilder) validate() error {
+	i := b.interactive
+	action := i.Action
+
+	if i.Type != WAITypeProduct && i.Body == nil {
+		return errors.New("body is required")
+	}
+	if i.Body != nil {
+		if err := maxLength("body", i.Body.Text, WhatsAppMaxBodyTextLength); err != nil {
+			return err
+		}
+	}
+	if i.Footer != nil {
+		if err := maxLength("footer", i.Footer.Text, WhatsAppMaxFooterTextLength); err != nil {
+			return err
+		}
+	}
+	if i.Header != nil && i.Header.Type == WAIHeaderTypeText {
+		if err := maxLength("header", i.Header.Text, WhatsAppMaxHeaderTextLength); err != nil {
+			return err
+		}
+	}
+
+	switch i.Type {
+	case WAITypeButton:
+		return validateWhatsAppButtons(action.ReplyButtons)
+	case WAITypeList:
+		if i.Header != nil && i.Header.Type != WAIHeaderTypeText {
+			return errors.New("header must be text")
+		}
+		if err := nonEmptyMaxLength("button", action.Button, WhatsAppMaxButtonTitleLength); err != nil {
+			return err
+		}
+		return validateWhatsAppSections(action.Sections, false)
+	case WAITypeProduct:
+		if i.Header != nil {
+			return errors.New("header is not allowed")
+		}
+		if action.CatalogId == "" || action.ProductRetailerId == "" {
+			return errors.New("catalog and product are required")
+		}
+	case WAITypeProductList:
+		if i.Header == nil || i.Header.Type != WAIHeaderTypeText || i.Header.Text == "" {
+			return errors.New("text header is required")
+		}
+		if action.CatalogId == "" {
+			return errors.New("catalog is required")
+		}
+		return validateWhatsAppSections(action.Sections, true)
+	}
+
+	return nil
+}
+
+func validateWhatsAppButtons(buttons []*WhatsAppInteractiveButton) error {
+	if len(buttons) == 0 || len(buttons) > WhatsAppMaxReplyButtons {
+		return fmt.Errorf("needs 1 to %d reply buttons, has %d", WhatsAppMaxReplyButtons, len(buttons))
+	}
+
+	ids := make(map[string]bool, len(buttons))
+	for _, button := range buttons {
+		if button.Id == "" || ids[button.Id] {
+			return fmt.Errorf("button %q needs a unique id", button.Title)
+		}
+		ids[button.Id] = true
+
+		if err := nonEmptyMaxLength("button title", button.Title, WhatsAppMaxButtonTitleLength); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateWhatsAppSections(sections []*WhatsAppInteractiveSection, products bool) error {
+	if len(sections) == 0 || len(sections) > WhatsAppMaxSections {
+		return fmt.Errorf("needs 1 to %d sections, has %d", WhatsAppMaxSections, len(sections))
+	}
+
+	ids := make(map[string]bool)
+	var count int
+	for _, section := range sections {
+		if len(sections) > 1 && section.Title == "" {
+			return errors.New("sections need a title when there is more than one")
+		}
+		if err := maxLength("section title", section.Title, WhatsAppMaxSectionTitleLength); err != nil {
+			return err
+		}
+
+		if products {
+			if len(section.ProductItems) == 0 {
+				return fmt.Errorf("section %q has no products", section.Title)
+			}
+			count += len(section.ProductItems)
+			continue
+		}
+
+		if len(section.Rows) == 0 {
+			return fmt.Errorf("section %q has no rows", section.Title)
+		}
+		for _, row := range section.Rows {
+			if row.Id == "" || ids[row.Id] {
+				return fmt.Errorf("row %q needs a unique id", row.Title)
+			}
+			ids[row.Id] = true
+
+			if err := nonEmptyMaxLength("row title", row.Title, WhatsAppMaxRowTitleLength); err != nil {
+				return err
+			}
+			if err := maxLength("row description", row.Description, WhatsAppMaxRowDescriptionLength); err != nil {
+				return err
+			}
+		}
+		count += len(section.Rows)
+	}
+
+	switch {
+	case products && count > WhatsAppMaxProducts:
+		return fmt.Errorf("at most %d products are allowed, has %d", WhatsAppMaxProducts, count)
+	case !products && count > WhatsAppMaxListRows:
+		return fmt.Errorf("at most %d rows are allowed, has %d", WhatsAppMaxListRows, count)
+	}
+
+	return nil
+}
+
+func nonEmptyMaxLength(name, s string, max int) error {
+	if s == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+
+	return maxLength(name, s, max)
+}
+
+// ErrNotInteractiveReply is returned by DecodeWhatsAppInteractiveReply for
+// messages that are not a reply to an interactive message.
+var ErrNotInteractiveReply = errors.New("message is not an interactive reply")
+
+// WhatsAppInteractiveReplyEvent is the option a customer selected in an
+// interactive message: a reply button or a list row.
+type WhatsAppInteractiveReplyEvent struct {
+	// Type is WAITypeButtonReply or WAITypeListReply.
+	Type WhatsAppInteractiveType
+
+	// ID is the id of the button or row that was selected.
+	ID          string
+	Title       string
+	Description string
+
+	MessageID      string
+	ConversationID string
+	From           string
+}
+
+// DecodeWhatsAppInteractiveReply gets the option that was selected in an
+// inbound interactive reply, e.g. from a MessageEvent. It returns
+// ErrNotInteractiveReply for other messages.
+func DecodeWhatsAppInteractiveReply(message *Message) (*WhatsAppInteractiveReplyEvent, error) {
+	if message == nil || message.Type != MessageTypeInteractive || message.Content == nil || message.Content.Interactive == nil {
+		return nil, ErrNotInteractiveReply
+	}
+
+	interactive := message.Content.Interactive
+	if interactive.Reply == nil || (interactive.Type != WAITypeButtonReply && interactive.Type != WAITypeListReply) {
+		return nil, ErrNotInteractiveReply
+	}
+
+	// Replies that can't be matched to the option aren't useful.
+	if interactive.Reply.Id == "" {
+		return nil, errors.New("interactive reply has no id")
+	}
+
+	return &WhatsAppInteractiveReplyEvent{
+		Type:           interactive.Type,
+		ID:             interactive.Reply.Id,
+		Title:          interactive.Reply.Text,
+		Description:    interactive.Reply.Description,
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		From:           message.From,
+	}, nil
+}
@@ -0,0 +1,194 @@
+package conversation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhatsAppButtons(t *testing.T) {
+	interactive, err := NewWhatsAppButtons("Did this answer your question?").
+		HeaderImage("https://example.com/faq.jpg").
+		Footer("Support").
+		ReplyButton("yes", "Yes").
+		ReplyButton("no", "No").
+		Build()
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(interactive)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "button",
+		"header": {"type": "image", "image": {"url": "https://example.com/faq.jpg"}},
+		"body": {"text": "Did this answer your question?"},
+		"footer": {"text": "Support"},
+		"action": {
+			"buttons": [
+				{"id": "yes", "type": "reply", "title": "Yes"},
+				{"id": "no", "type": "reply", "title": "No"}
+			]
+		}
+	}`, string(data))
+}
+
+func TestWhatsAppInteractiveActionDeprecatedButtons(t *testing.T) {
+	data, err := json.Marshal(&WhatsAppInteractiveAction{
+		Buttons: &WhatsAppInteractiveButton{Id: "yes", Type: "reply", Title: "Yes"},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"buttons": [{"id": "yes", "type": "reply", "title": "Yes"}]}`, string(data))
+}
+
+func TestWhatsAppList(t *testing.T) {
+	builder := NewWhatsAppList("Pick a time slot", "Slots").
+		HeaderText("Appointments").
+		Section("Monday").
+		Row("mon-9", "09:00", "With Jen").
+		Row("mon-10", "10:00", "").
+		Section("Tuesday").
+		Row("tue-9", "09:00", "")
+	interactive, err := builder.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "Slots", interactive.Action.Button)
+	assert.Len(t, interactive.Action.Sections, 2)
+	assert.Equal(t, "mon-10", interactive.Action.Sections[0].Rows[1].Id)
+
+	// The builder can be reused without changing the message it built.
+	second, err := builder.Row("tue-10", "10:00", "").HeaderText("Next week").Build()
+	assert.NoError(t, err)
+	assert.Len(t, second.Action.Sections[1].Rows, 2)
+	assert.Len(t, interactive.Action.Sections[1].Rows, 1)
+	assert.Equal(t, "Appointments", interactive.Header.Text)
+
+	second.Action.Sections[0].Rows[0].Title = "08:00"
+	assert.Equal(t, "09:00", interactive.Action.Sections[0].Rows[0].Title)
+}
+
+func TestWhatsAppProducts(t *testing.T) {
+	product, err := NewWhatsAppProduct("catid", "sku-1", "").Build()
+	assert.NoError(t, err)
+	assert.Nil(t, product.Body)
+
+	productList, err := NewWhatsAppProductList("catid", "Our picks", "Have a look").
+		Product("sku-1").
+		Product("sku-2").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "Our picks", productList.Header.Text)
+	assert.Len(t, productList.Action.Sections[0].ProductItems, 2)
+}
+
+func TestWhatsAppInteractiveBuilderLimits(t *testing.T) {
+	tooManyRows := NewWhatsAppList("Pick one", "Options")
+	for _, id := range strings.Split("a b c d e f g h i j k", " ") {
+		tooManyRows.Row(id, id, "")
+	}
+
+	tooManyProducts := NewWhatsAppProductList("catid", "Our picks", "Have a look")
+	for i := 0; i < 31; i++ {
+		tooManyProducts.Product(strings.Repeat("x", i+1))
+	}
+
+	cases := []struct {
+		name    string
+		builder *WhatsAppInteractiveBuilder
+		wantErr string
+	}{
+		{
+			"too many buttons",
+			NewWhatsAppButtons("Pick one").ReplyButton("a", "A").ReplyButton("b", "B").ReplyButton("c", "C").ReplyButton("d", "D"),
+			"whatsapp button: needs 1 to 3 reply buttons, has 4",
+		},
+		{
+			"no buttons",
+			NewWhatsAppButtons("Pick one"),
+			"whatsapp button: needs 1 to 3 reply buttons, has 0",
+		},
+		{
+			"button title too long",
+			NewWhatsAppButtons("Pick one").ReplyButton("a", strings.Repeat("a", 21)),
+			"whatsapp button: button title exceeds 20 characters",
+		},
+		{
+			"duplicate button id",
+			NewWhatsAppButtons("Pick one").ReplyButton("a", "A").ReplyButton("a", "B"),
+			`whatsapp button: button "B" needs a unique id`,
+		},
+		{
+			"no body",
+			NewWhatsAppButtons("").ReplyButton("a", "A"),
+			"whatsapp button: body is required",
+		},
+		{
+			"too many rows",
+			tooManyRows,
+			"whatsapp list: at most 10 rows are allowed, has 11",
+		},
+		{
+			"row title too long",
+			NewWhatsAppList("Pick one", "Options").Row("a", strings.Repeat("a", 25), ""),
+			"whatsapp list: row title exceeds 24 characters",
+		},
+		{
+			"untitled section",
+			NewWhatsAppList("Pick one", "Options").Row("a", "A", "").Section("More").Row("b", "B", ""),
+			"whatsapp list: sections need a title when there is more than one",
+		},
+		{
+			"image header on list",
+			NewWhatsAppList("Pick one", "Options").HeaderImage("https://example.com/faq.jpg").Row("a", "A", ""),
+			"whatsapp list: header must be text",
+		},
+		{
+			"too many products",
+			tooManyProducts,
+			"whatsapp product_list: at most 30 products are allowed, has 31",
+		},
+		{
+			"product without catalog",
+			NewWhatsAppProduct("", "sku-1", ""),
+			"whatsapp product: catalog and product are required",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.builder.Build()
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestDecodeWhatsAppInteractiveReply(t *testing.T) {
+	message := &Message{}
+	err := json.Unmarshal([]byte(`{
+		"id": "mesid",
+		"conversationId": "convid",
+		"from": "+31612345678",
+		"type": "interactive",
+		"content": {
+			"interactive": {
+				"type": "list_reply",
+				"reply": {"id": "mon-9", "text": "09:00", "description": "With Jen"}
+			}
+		}
+	}`), message)
+	assert.NoError(t, err)
+
+	reply, err := DecodeWhatsAppInteractiveReply(message)
+	assert.NoError(t, err)
+	assert.Equal(t, &WhatsAppInteractiveReplyEvent{
+		Type:           WAITypeListReply,
+		ID:             "mon-9",
+		Title:          "09:00",
+		Description:    "With Jen",
+		MessageID:      "mesid",
+		ConversationID: "convid",
+		From:           "+31612345678",
+	}, reply)
+
+	_, err = DecodeWhatsAppInteractiveReply(&Message{Type: MessageTypeText, Content: &MessageContent{Text: "Hi"}})
+	assert.Equal(t, ErrNotInteractiveReply, err)
+}
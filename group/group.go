@@ -139,7 +139,8 @@ func validateUpdate(request *Request) error {
 	return nil
 }
 
-// AddContacts adds a maximum of 50 contacts to the group.
+// AddContacts adds a maximum of 50 contacts to the group. Use
+// AddContactsInBatches to add more.
 func AddContacts(c messagebird.Client, groupID string, contactIDs []string) error {
 	if err := validateAddContacts(contactIDs); err != nil {
 		return err
@@ -190,6 +191,22 @@ func ListContacts(c messagebird.Client, groupID string, options *messagebird.Pag
 	return contacts, nil
 }
 
+// ListByContact lists the groups a contact is a member of, using the
+// contact's Groups.HRef link.
+func ListByContact(c messagebird.Client, ct *contact.Contact, options *messagebird.PaginationRequest) (*Groups, error) {
+	href := ct.Groups.HRef
+	if href == "" {
+		href = fmt.Sprintf("contacts/%s/%s", ct.ID, path)
+	}
+
+	groupList := &Groups{}
+	if err := c.Request(groupList, http.MethodGet, href+"?"+options.QueryParams(), nil); err != nil {
+		return nil, err
+	}
+
+	return groupList, nil
+}
+
 // RemoveContact removes the contact from a group. If nil is returned, the
 // operation was successful.
 func RemoveContact(c messagebird.Client, groupID, contactID string) error {
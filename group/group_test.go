@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/messagebird/go-rest-api/v9/contact"
 	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
 	"github.com/stretchr/testify/assert"
 )
@@ -172,3 +173,24 @@ func TestRemoveContact(t *testing.T) {
 
 	mbtest.AssertEndpointCalled(t, http.MethodDelete, "/groups/group-id/contacts/contact-id")
 }
+
+func TestListByContact(t *testing.T) {
+	mbtest.WillReturnTestdata(t, "groupListObject.json", http.StatusOK)
+	client := mbtest.Client(t)
+
+	ct := &contact.Contact{ID: "contact-id"}
+	ct.Groups.HRef = "https://rest.messagebird.com/contacts/contact-id/groups"
+
+	list, err := ListByContact(client, ct, messagebird.DefaultPagination)
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 2)
+	assert.Equal(t, "first-id", list.Items[0].ID)
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/contacts/contact-id/groups")
+	assert.Equal(t, "limit=20&offset=0", mbtest.Request.URL.RawQuery)
+
+	_, err = ListByContact(client, &contact.Contact{ID: "other-id"}, nil)
+	assert.NoError(t, err)
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/contacts/other-id/groups")
+}
@@ -0,0 +1,145 @@
+package group
+
+import (
+	"sort"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+)
+
+// listContactsPageSize is the number of contacts requested per page when
+// listing all members of a group.
+const listContactsPageSize = 100
+
+// SyncResult describes the difference between a group's members and the
+// desired members.
+type SyncResult struct {
+	// Added and Removed are the IDs of the contacts that are (to be) added to
+	// and removed from the group, sorted.
+	Added   []string
+	Removed []string
+
+	// Unchanged is the number of contacts that are a member of the group and
+	// should remain so.
+	Unchanged int
+}
+
+// AddContactsInBatches adds any number of contacts to the group. It calls
+// AddContacts for every 50 contacts. If an error occurs, the contacts that
+// were added so far are returned with it.
+func AddContactsInBatches(c messagebird.Client, groupID string, contactIDs []string) ([]string, error) {
+	added := make([]string, 0, len(contactIDs))
+
+	for start := 0; start < len(contactIDs); start += maximumContactsPerRequest {
+		end := start + maximumContactsPerRequest
+		if end > len(contactIDs) {
+			end = len(contactIDs)
+		}
+
+		if err := AddContacts(c, groupID, contactIDs[start:end]); err != nil {
+			return added, err
+		}
+		added = append(added, contactIDs[start:end]...)
+	}
+
+	return added, nil
+}
+
+// ListAllContactIDs gets the IDs of all contacts that are a member of the
+// group, paging through ListContacts.
+func ListAllContactIDs(c messagebird.Client, groupID string) ([]string, error) {
+	var ids []string
+
+	for offset := 0; ; {
+		contacts, err := ListContacts(c, groupID, &messagebird.PaginationRequest{Limit: listContactsPageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, contact := range contacts.Items {
+			ids = append(ids, contact.ID)
+		}
+
+		offset += len(contacts.Items)
+		if len(contacts.Items) == 0 || offset >= contacts.TotalCount {
+			return ids, nil
+		}
+	}
+}
+
+// Diff works out which contacts must be added to and removed from the group
+// for its members to be exactly contactIDs. It does not change the group.
+func Diff(c messagebird.Client, groupID string, contactIDs []string) (*SyncResult, error) {
+	current, err := ListAllContactIDs(c, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return diff(current, contactIDs), nil
+}
+
+// Sync makes the group's members exactly contactIDs. It adds the missing
+// contacts in batches of 50 and removes the others one by one. The result
+// describes the changes that were made. If an error occurs, the changes made
+// so far are returned with it.
+func Sync(c messagebird.Client, groupID string, contactIDs []string) (*SyncResult, error) {
+	planned, err := Diff(c, groupID, contactIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{
+		Unchanged: planned.Unchanged,
+	}
+
+	if result.Added, err = AddContactsInBatches(c, groupID, planned.Added); err != nil {
+		return result, err
+	}
+
+	result.Removed = make([]string, 0, len(planned.Removed))
+	for _, contactID := range planned.Removed {
+		if err := RemoveContact(c, groupID, contactID); err != nil {
+			return result, err
+		}
+		result.Removed = append(result.Removed, contactID)
+	}
+
+	return result, nil
+}
+
+// diff compares the current members of a group to the desired ones.
+func diff(current, desired []string) *SyncResult {
+	isCurrent := make(map[string]bool, len(current))
+	for _, id := range current {
+		isCurrent[id] = true
+	}
+
+	result := &SyncResult{
+		Added:   []string{},
+		Removed: []string{},
+	}
+
+	isDesired := make(map[string]bool, len(desired))
+	for _, id := range desired {
+		if isDesired[id] {
+			continue
+		}
+		isDesired[id] = true
+
+		if isCurrent[id] {
+			result.Unchanged++
+		} else {
+			result.Added = append(result.Added, id)
+		}
+	}
+
+	for id := range isCurrent {
+		if !isDesired[id] {
+			result.Removed = append(result.Removed, id)
+		}
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+
+	return result
+}
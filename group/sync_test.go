@@ -0,0 +1,106 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// willReturnSyncTestdata makes the test server accept adding contacts to and
+// removing contacts from the group.
+func willReturnSyncTestdata(t *testing.T) {
+	mbtest.WillReturnFor(t, http.MethodPut, "/groups/group-id/contacts", []byte(""), http.StatusNoContent)
+	mbtest.WillReturnFor(t, http.MethodDelete, "/groups/group-id/contacts/remove-1", []byte(""), http.StatusNoContent)
+	mbtest.WillReturnFor(t, http.MethodDelete, "/groups/group-id/contacts/remove-2", []byte(""), http.StatusNoContent)
+}
+
+// syncWrites gets the requests that changed the group since the last
+// mbtest.ResetRequests.
+func syncWrites() []string {
+	var writes []string
+	for _, request := range mbtest.Requests() {
+		if request.Method != http.MethodGet {
+			writes = append(writes, strings.TrimSpace(request.Method+" "+request.URL.Path+" "+string(request.Body)))
+		}
+	}
+
+	return writes
+}
+
+func contactIDs(prefix string, n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s-%03d", prefix, i)
+	}
+
+	return ids
+}
+
+func TestAddContactsInBatches(t *testing.T) {
+	willReturnSyncTestdata(t)
+	mbtest.ResetRequests()
+	client := mbtest.Client(t)
+
+	ids := contactIDs("new", 120)
+	added, err := AddContactsInBatches(client, "group-id", ids)
+	assert.NoError(t, err)
+	assert.Equal(t, ids, added)
+
+	assert.Equal(t, []string{
+		"PUT /groups/group-id/contacts " + addContactsData(ids[:50]),
+		"PUT /groups/group-id/contacts " + addContactsData(ids[50:100]),
+		"PUT /groups/group-id/contacts " + addContactsData(ids[100:]),
+	}, syncWrites())
+}
+
+func TestSync(t *testing.T) {
+	// The group has keep-000 to keep-149, remove-1 and remove-2 as members.
+	willReturnSyncTestdata(t)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/groups/group-id/contacts?limit=100&offset=0", "groupContactListPage1Object.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/groups/group-id/contacts?limit=100&offset=100", "groupContactListPage2Object.json", http.StatusOK)
+	mbtest.ResetRequests()
+	client := mbtest.Client(t)
+
+	desired := append(contactIDs("keep", 150), contactIDs("new", 60)...)
+	desired = append(desired, "new-000")
+
+	planned, err := Diff(client, "group-id", desired)
+	assert.NoError(t, err)
+	assert.Equal(t, contactIDs("new", 60), planned.Added)
+	assert.Equal(t, []string{"remove-1", "remove-2"}, planned.Removed)
+	assert.Equal(t, 150, planned.Unchanged)
+	assert.Len(t, mbtest.Requests(), 2)
+	assert.Empty(t, syncWrites())
+
+	mbtest.ResetRequests()
+	result, err := Sync(client, "group-id", desired)
+	assert.NoError(t, err)
+	assert.Equal(t, planned, result)
+
+	assert.Equal(t, []string{
+		"PUT /groups/group-id/contacts " + addContactsData(contactIDs("new", 50)),
+		"PUT /groups/group-id/contacts " + addContactsData(contactIDs("new", 60)[50:]),
+		"DELETE /groups/group-id/contacts/remove-1",
+		"DELETE /groups/group-id/contacts/remove-2",
+	}, syncWrites())
+}
+
+func TestSyncUnchanged(t *testing.T) {
+	willReturnSyncTestdata(t)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/groups/group-id/contacts", "groupContactListObject.json", http.StatusOK)
+	mbtest.ResetRequests()
+	client := mbtest.Client(t)
+
+	result, err := Sync(client, "group-id", []string{"third-contact-id", "first-contact-id", "second-contact-id"})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Added)
+	assert.Empty(t, result.Removed)
+	assert.Equal(t, 3, result.Unchanged)
+
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/groups/group-id/contacts")
+	assert.Empty(t, syncWrites())
+}
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"sync"
 	"testing"
 )
 
@@ -26,6 +27,21 @@ var server *httptest.Server
 var responseBody []byte
 var status int
 
+// mu guards routes and requests, as the server handles requests
+// concurrently.
+var mu sync.Mutex
+
+// routes holds the responses set with WillReturnFor, by method and path.
+var routes = map[string][]*response{}
+
+// requests holds the requests received since the last ResetRequests.
+var requests []request
+
+type response struct {
+	body   []byte
+	status int
+}
+
 // EnableServer starts a fake server, runs the test and closes the server.
 func EnableServer(m *testing.M) {
 	initAndStartServer()
@@ -37,7 +53,7 @@ func EnableServer(m *testing.M) {
 
 func initAndStartServer() {
 	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		Request = request{
+		req := request{
 			ContentType: r.Header.Get("Content-Type"),
 			Method:      r.Method,
 			URL:         r.URL,
@@ -47,20 +63,45 @@ func initAndStartServer() {
 
 		// Reading from the request body is fine, as it's not used elsewhere.
 		// Server always returns fake data/testdata.
-		Request.Body, err = ioutil.ReadAll(r.Body)
+		req.Body, err = ioutil.ReadAll(r.Body)
 		if err != nil {
 			panic(err.Error())
 		}
 
-		// status and responseBody are defined in returns.go.
+		mu.Lock()
+		Request = req
+		requests = append(requests, req)
+		resp := nextResponse(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery)
+		if resp == nil {
+			resp = nextResponse(r.Method + " " + r.URL.Path)
+		}
+		if resp == nil {
+			resp = &response{body: responseBody, status: status}
+		}
+		mu.Unlock()
+
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(status)
-		if _, err := w.Write(responseBody); err != nil {
+		w.WriteHeader(resp.status)
+		if _, err := w.Write(resp.body); err != nil {
 			panic(err.Error())
 		}
 	}))
 }
 
+// nextResponse gets the response for the route with the given key, or nil if
+// there is none. The last response of a route is repeated. mu must be held.
+func nextResponse(key string) *response {
+	responses := routes[key]
+	if len(responses) == 0 {
+		return nil
+	}
+	if len(responses) > 1 {
+		routes[key] = responses[1:]
+	}
+
+	return responses[0]
+}
+
 func closeServer() {
 	server.Close()
 }
@@ -89,6 +130,48 @@ func WillReturnTestdata(t *testing.T, relativePath string, s int) {
 	WillReturn(Testdata(t, relativePath), s)
 }
 
+// WillReturnFor sets the response body (b) and status (s) for requests with
+// the given method and path, for tests that make several requests. The path
+// may include a query, which then has to match exactly. Other requests get the
+// response set with WillReturn. Calling it again for the same request adds a
+// response: they are returned in order, and the last one is repeated. The
+// responses are removed when the test ends.
+func WillReturnFor(t *testing.T, method, path string, b []byte, s int) {
+	key := method + " " + path
+
+	mu.Lock()
+	routes[key] = append(routes[key], &response{body: b, status: s})
+	mu.Unlock()
+
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(routes, key)
+		mu.Unlock()
+	})
+}
+
+// WillReturnTestdataFor is like WillReturnFor, but responds with the bytes of
+// the relativePath file in the testdata directory.
+func WillReturnTestdataFor(t *testing.T, method, path, relativePath string, s int) {
+	WillReturnFor(t, method, path, Testdata(t, relativePath), s)
+}
+
+// ResetRequests forgets the requests received so far, see Requests.
+func ResetRequests() {
+	mu.Lock()
+	requests = nil
+	mu.Unlock()
+}
+
+// Requests gets the requests received since the last ResetRequests, in the
+// order they were received.
+func Requests() []request {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return append([]request(nil), requests...)
+}
+
 // WillReturnAccessKeyError sets the response body and status for requests to
 // indicate the request is not allowed due to an incorrect access key.
 func WillReturnAccessKeyError() {
@@ -0,0 +1,52 @@
+// Package pool runs functions in parallel with a maximum concurrency. It is
+// used by the batch operations of several packages.
+package pool
+
+import "sync"
+
+// Pool runs functions in parallel, but at most a fixed number at a time.
+type Pool struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+// New creates a Pool that runs at most concurrency functions at a time. A
+// concurrency of zero or less is treated as 1.
+func New(concurrency int) *Pool {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	return &Pool{sem: make(chan struct{}, concurrency)}
+}
+
+// Go runs fn in a new goroutine. It blocks while the maximum number of
+// functions is running, so a producer never gets far ahead of the work.
+func (p *Pool) Go(fn func()) {
+	p.wg.Add(1)
+	p.sem <- struct{}{}
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+
+		fn()
+	}()
+}
+
+// Wait waits until all functions passed to Go have returned.
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+// Run calls fn for 0 to n-1 with at most concurrency calls in parallel, and
+// waits for all of them to return.
+func Run(n, concurrency int, fn func(i int)) {
+	p := New(concurrency)
+	for i := 0; i < n; i++ {
+		i := i
+		p.Go(func() { fn(i) })
+	}
+	p.Wait()
+}
@@ -0,0 +1,93 @@
+// Package reconcile plans and applies the changes that turn a set of existing
+// resources into a desired set. It is shared by the webhook reconcilers of the
+// conversation and voice packages, which only differ in how they compare and
+// update their webhooks.
+package reconcile
+
+import "strings"
+
+// Action is what is done to reconcile a resource.
+type Action string
+
+const (
+	Create  Action = "create"
+	Update  Action = "update"
+	Disable Action = "disable"
+	Delete  Action = "delete"
+)
+
+// Step is a single change. Desired and Existing are indexes in the slices
+// that were passed to Plan, or -1 if the step has none.
+type Step struct {
+	Action   Action
+	Desired  int
+	Existing int
+}
+
+// Plan matches desired and existing resources by key. The first unmatched
+// existing resource with the same key as a desired one is its match.
+//
+// A desired resource without match is created, and one whose match is not
+// inSync is updated. For existing resources without match, extra is called
+// to get the action; if it returns an empty Action, the resource is left
+// alone. Creates and updates are planned in the order of desired, before the
+// extra resources in the order of existing, so replacements are in place
+// before anything is removed.
+func Plan(desired, existing []string, inSync func(d, e int) bool, extra func(e int) Action) []Step {
+	byKey := make(map[string][]int)
+	for e, key := range existing {
+		byKey[key] = append(byKey[key], e)
+	}
+
+	var steps []Step
+	matched := make([]bool, len(existing))
+	for d, key := range desired {
+		candidates := byKey[key]
+		if len(candidates) == 0 {
+			steps = append(steps, Step{Action: Create, Desired: d, Existing: -1})
+			continue
+		}
+
+		e := candidates[0]
+		byKey[key] = candidates[1:]
+		matched[e] = true
+
+		if !inSync(d, e) {
+			steps = append(steps, Step{Action: Update, Desired: d, Existing: e})
+		}
+	}
+
+	for e := range existing {
+		if matched[e] {
+			continue
+		}
+		if action := extra(e); action != "" {
+			steps = append(steps, Step{Action: action, Desired: -1, Existing: e})
+		}
+	}
+
+	return steps
+}
+
+// Apply calls apply for the steps 0 to n-1 in order. A failed step doesn't
+// stop the others: the first error is returned after all steps were tried.
+func Apply(n int, apply func(i int) error) error {
+	var firstErr error
+	for i := 0; i < n; i++ {
+		if err := apply(i); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Describe joins the descriptions of the steps 0 to n-1 with a line per step.
+func Describe(n int, describe func(i int) string) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = describe(i)
+	}
+
+	return strings.Join(lines, "\n")
+}
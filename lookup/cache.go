@@ -0,0 +1,127 @@
+package lookup
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache stores lookup results for a limited amount of time. Implementations
+// must be safe for concurrent use. LRUCache is an in-memory implementation.
+type Cache interface {
+	// Get returns the value stored for key. It reports false if there is no
+	// such value, or if it has expired.
+	Get(key string) (interface{}, bool)
+
+	// Set stores value for key. It expires after ttl. A ttl of zero or less
+	// means the value does not expire.
+	Set(key string, value interface{}, ttl time.Duration)
+
+	// Delete removes the value stored for key, if any.
+	Delete(key string)
+}
+
+// LRUCache is an in-memory Cache that holds a maximum number of entries. When
+// it is full, the least recently used entry is evicted.
+type LRUCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+
+	// now is the clock entries expire by. Tests move it forward instead of
+	// sleeping.
+	now func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// NewLRUCache creates a new LRUCache that holds up to size entries.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get implements Cache.
+func (c *LRUCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return entry.value, true
+}
+
+// Set implements Cache.
+func (c *LRUCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	for c.size > 0 && c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete implements Cache.
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// Len returns the number of entries in the cache, including those that have
+// expired but were not evicted yet.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// remove must be called with c.mu held.
+func (c *LRUCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,66 @@
+package lookup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCacheEviction(t *testing.T) {
+	cache := NewLRUCache(2)
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+
+	// Using "a" makes "b" the least recently used entry.
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	cache.Set("c", 3, 0)
+	assert.Equal(t, 2, cache.Len())
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+
+	val, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+
+	val, ok = cache.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, val)
+}
+
+func TestLRUCacheExpiry(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cache := NewLRUCache(10)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", 1, time.Minute)
+	cache.Set("b", 2, 0)
+
+	now = now.Add(59 * time.Second)
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, cache.Len())
+
+	_, ok = cache.Get("b")
+	assert.True(t, ok)
+}
+
+func TestLRUCacheDelete(t *testing.T) {
+	cache := NewLRUCache(10)
+
+	cache.Set("a", 1, 0)
+	cache.Delete("a")
+
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
@@ -0,0 +1,221 @@
+package lookup
+
+import (
+	"sync"
+	"time"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/hlr"
+	"github.com/messagebird/go-rest-api/v9/internal/pool"
+)
+
+const (
+	// DefaultTTL is how long a CachedReader caches lookups by default.
+	// Country, type and formats of a number rarely change.
+	DefaultTTL = 24 * time.Hour
+
+	// DefaultHLRTTL is how long a CachedReader caches HLR lookups by default.
+	// The network and status of a subscriber change more often than the
+	// number itself.
+	DefaultHLRTTL = time.Hour
+
+	// DefaultBatchConcurrency is the number of lookups a batch performs in
+	// parallel when no concurrency is given.
+	DefaultBatchConcurrency = 10
+)
+
+// CachedReader performs lookups like Read and ReadHLR, but stores the results
+// in a Cache. Numbers are normalized with Parse before being used as cache
+// key, so different notations of the same number share a cache entry.
+// Concurrent lookups for the same number result in a single API request.
+//
+// Errors are never cached. A CachedReader is safe for concurrent use.
+type CachedReader struct {
+	Client messagebird.Client
+
+	// Cache stores the results. Nothing is cached when Cache is nil.
+	Cache Cache
+
+	// TTL and HLRTTL are the times results of Read and ReadHLR are cached
+	// for.
+	TTL    time.Duration
+	HLRTTL time.Duration
+
+	mu       sync.Mutex
+	inflight map[string]*inflightCall
+}
+
+// inflightCall is a lookup that is currently being performed.
+type inflightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// BatchResult is the result of a single number in a batch lookup. Exactly one
+// of Lookup, HLR and Err is set.
+type BatchResult struct {
+	PhoneNumber string
+	Lookup      *Lookup
+	HLR         *hlr.HLR
+	Err         error
+}
+
+const (
+	cacheKeyLookup = "lookup"
+	cacheKeyHLR    = "hlr"
+)
+
+// NewCachedReader creates a new CachedReader with the default TTLs.
+func NewCachedReader(c messagebird.Client, cache Cache) *CachedReader {
+	return &CachedReader{
+		Client: c,
+		Cache:  cache,
+		TTL:    DefaultTTL,
+		HLRTTL: DefaultHLRTTL,
+	}
+}
+
+// Read performs a lookup for the specified number, unless a cached result is
+// available.
+func (r *CachedReader) Read(phoneNumber string, params *Params) (*Lookup, error) {
+	val, err := r.cached(cacheKey(cacheKeyLookup, phoneNumber, params), r.TTL, func() (interface{}, error) {
+		lookup, err := Read(r.Client, phoneNumber, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return *lookup, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := val.(Lookup)
+	if lookup.HLR != nil {
+		hlrCopy := *lookup.HLR
+		lookup.HLR = &hlrCopy
+	}
+
+	return &lookup, nil
+}
+
+// ReadHLR performs a HLR lookup for the specified number, unless a cached
+// result is available.
+func (r *CachedReader) ReadHLR(phoneNumber string, params *Params) (*hlr.HLR, error) {
+	val, err := r.cached(cacheKey(cacheKeyHLR, phoneNumber, params), r.HLRTTL, func() (interface{}, error) {
+		h, err := ReadHLR(r.Client, phoneNumber, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return *h, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h := val.(hlr.HLR)
+
+	return &h, nil
+}
+
+// ReadBatch performs Read for all phoneNumbers, with at most concurrency
+// lookups in parallel. If concurrency is zero or less,
+// DefaultBatchConcurrency is used. The results are in the same order as
+// phoneNumbers.
+func (r *CachedReader) ReadBatch(phoneNumbers []string, params *Params, concurrency int) []BatchResult {
+	return batch(phoneNumbers, concurrency, func(result *BatchResult) {
+		result.Lookup, result.Err = r.Read(result.PhoneNumber, params)
+	})
+}
+
+// ReadHLRBatch performs ReadHLR for all phoneNumbers, with at most
+// concurrency lookups in parallel. If concurrency is zero or less,
+// DefaultBatchConcurrency is used. The results are in the same order as
+// phoneNumbers.
+func (r *CachedReader) ReadHLRBatch(phoneNumbers []string, params *Params, concurrency int) []BatchResult {
+	return batch(phoneNumbers, concurrency, func(result *BatchResult) {
+		result.HLR, result.Err = r.ReadHLR(result.PhoneNumber, params)
+	})
+}
+
+// cached gets the value for key from the cache. If it is not available, fn is
+// called to obtain it. Concurrent calls for the same key wait for the first
+// one to complete.
+func (r *CachedReader) cached(key string, ttl time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	if r.Cache != nil {
+		if val, ok := r.Cache.Get(key); ok {
+			return val, nil
+		}
+	}
+
+	r.mu.Lock()
+	if call, ok := r.inflight[key]; ok {
+		r.mu.Unlock()
+		call.wg.Wait()
+		return call.val, call.err
+	}
+
+	// A call for key may have completed since the cache was checked: it is
+	// cached before it is removed from inflight.
+	if r.Cache != nil {
+		if val, ok := r.Cache.Get(key); ok {
+			r.mu.Unlock()
+			return val, nil
+		}
+	}
+
+	call := &inflightCall{}
+	call.wg.Add(1)
+	if r.inflight == nil {
+		r.inflight = make(map[string]*inflightCall)
+	}
+	r.inflight[key] = call
+	r.mu.Unlock()
+
+	call.val, call.err = fn()
+	if call.err == nil && r.Cache != nil {
+		r.Cache.Set(key, call.val, ttl)
+	}
+	call.wg.Done()
+
+	r.mu.Lock()
+	delete(r.inflight, key)
+	r.mu.Unlock()
+
+	return call.val, call.err
+}
+
+// cacheKey gets the key a lookup is cached under. Numbers that can be parsed
+// offline are keyed by their E.164 format.
+func cacheKey(kind, phoneNumber string, params *Params) string {
+	var countryCode, reference string
+	if params != nil {
+		countryCode = params.CountryCode
+		reference = params.Reference
+	}
+
+	number := phoneNumber + "/" + countryCode
+	if lookup, err := Parse(phoneNumber, params); err == nil {
+		number = lookup.Formats.E164
+	}
+
+	return kind + ":" + number + ":" + reference
+}
+
+// batch calls fn for every phone number, with at most concurrency calls in
+// parallel.
+func batch(phoneNumbers []string, concurrency int, fn func(result *BatchResult)) []BatchResult {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	results := make([]BatchResult, len(phoneNumbers))
+	pool.Run(len(phoneNumbers), concurrency, func(i int) {
+		results[i].PhoneNumber = phoneNumbers[i]
+		fn(&results[i])
+	})
+
+	return results
+}
@@ -0,0 +1,118 @@
+package lookup
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func cachedReaderTestClient(t *testing.T) *CachedReader {
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/lookup/31624971134", "lookupObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/lookup/31624971134/hlr", "lookupHLRObject.json", http.StatusOK)
+	mbtest.WillReturnTestdataFor(t, http.MethodGet, "/lookup/31600000000", "lookupNotFoundError.json", http.StatusNotFound)
+	mbtest.ResetRequests()
+
+	return NewCachedReader(mbtest.Client(t), NewLRUCache(100))
+}
+
+func TestCachedReaderRead(t *testing.T) {
+	reader := cachedReaderTestClient(t)
+
+	lookup, err := reader.Read("31624971134", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "+31 6 24971134", lookup.Formats.International)
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/lookup/31624971134")
+
+	// Different notations of the same number hit the cache.
+	lookup, err = reader.Read("06 24971134", &Params{CountryCode: "NL"})
+	assert.NoError(t, err)
+	assert.Equal(t, "+31 6 24971134", lookup.Formats.International)
+
+	// Modifying a result does not modify the cache.
+	lookup.HLR.Status = "absent"
+	lookup, err = reader.Read("+31624971134", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "active", lookup.HLR.Status)
+
+	assert.Len(t, mbtest.Requests(), 1)
+
+	_, err = reader.ReadHLR("31624971134", nil)
+	assert.NoError(t, err)
+	hlr, err := reader.ReadHLR("31624971134", nil)
+	assert.NoError(t, err)
+	checkHLR(t, hlr)
+	mbtest.AssertEndpointCalled(t, http.MethodGet, "/lookup/31624971134/hlr")
+
+	assert.Len(t, mbtest.Requests(), 2)
+}
+
+func TestCachedReaderErrorNotCached(t *testing.T) {
+	reader := cachedReaderTestClient(t)
+
+	_, err := reader.Read("31600000000", nil)
+	assert.EqualError(t, err, "API errors: lookup not found")
+
+	_, err = reader.Read("31600000000", nil)
+	assert.Error(t, err)
+
+	assert.Len(t, mbtest.Requests(), 2)
+}
+
+func TestCachedReaderReadBatch(t *testing.T) {
+	reader := cachedReaderTestClient(t)
+
+	phoneNumbers := []string{"31624971134", "31600000000", "+31 6 24971134", "31624971134"}
+	results := reader.ReadBatch(phoneNumbers, nil, 2)
+	assert.Len(t, results, len(phoneNumbers))
+
+	for i, result := range results {
+		assert.Equal(t, phoneNumbers[i], result.PhoneNumber)
+		if i == 1 {
+			assert.Error(t, result.Err)
+			assert.Nil(t, result.Lookup)
+			continue
+		}
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, "+31624971134", result.Lookup.Formats.E164)
+	}
+
+	// All notations of the valid number share a single request.
+	assert.Len(t, mbtest.Requests(), 2)
+
+	results = reader.ReadHLRBatch([]string{"31624971134"}, nil, 0)
+	assert.NoError(t, results[0].Err)
+	checkHLR(t, results[0].HLR)
+}
+
+// missOnceCache misses on the first Get, as if the value was cached by another
+// call right after that Get.
+type missOnceCache struct {
+	Cache
+	missed bool
+}
+
+func (c *missOnceCache) Get(key string) (interface{}, bool) {
+	if !c.missed {
+		c.missed = true
+		return nil, false
+	}
+
+	return c.Cache.Get(key)
+}
+
+func TestCachedReaderCachedAfterMiss(t *testing.T) {
+	reader := cachedReaderTestClient(t)
+
+	_, err := reader.Read("31624971134", nil)
+	assert.NoError(t, err)
+
+	reader.Cache = &missOnceCache{Cache: reader.Cache}
+	lookup, err := reader.Read("31624971134", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "+31 6 24971134", lookup.Formats.International)
+
+	assert.Len(t, mbtest.Requests(), 1)
+}
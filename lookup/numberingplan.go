@@ -0,0 +1,188 @@
+package lookup
+
+import (
+	_ "embed"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// numberingPlanJSON holds the numbering plan metadata used for parsing and
+// formatting numbers without calling the API. It only describes the subset of
+// countries listed in numberingplan.json.
+//
+//go:embed numberingplan.json
+var numberingPlanJSON []byte
+
+// numberingPlan describes the numbers of a single country.
+type numberingPlan struct {
+	CountryCode    string
+	CountryPrefix  int
+	NationalPrefix string
+
+	// LeadingDigits is only set for countries that share their country
+	// prefix with another one (e.g. US and CA). It selects this plan based on
+	// the first digits of the national number.
+	LeadingDigits *regexp.Regexp
+
+	// Pattern matches every valid national number.
+	Pattern *regexp.Regexp
+
+	Types   []numberType
+	Formats []numberFormat
+}
+
+// numberType matches national numbers to a type, as returned by the API in
+// Lookup.Type.
+type numberType struct {
+	Type    string
+	Pattern *regexp.Regexp
+}
+
+// numberFormat describes how national numbers are grouped for display.
+type numberFormat struct {
+	LeadingDigits *regexp.Regexp
+	Pattern       *regexp.Regexp
+
+	// Format is the template used for the international format. It is also
+	// used for the national format, prefixed with the national prefix, unless
+	// NationalFormat is set.
+	Format         string
+	NationalFormat string
+}
+
+type jsonNumberingPlan struct {
+	CountryCode    string `json:"countryCode"`
+	CountryPrefix  int    `json:"countryPrefix"`
+	NationalPrefix string `json:"nationalPrefix"`
+	LeadingDigits  string `json:"leadingDigits"`
+	Pattern        string `json:"pattern"`
+	Types          []struct {
+		Type    string `json:"type"`
+		Pattern string `json:"pattern"`
+	} `json:"types"`
+	Formats []struct {
+		LeadingDigits  string `json:"leadingDigits"`
+		Pattern        string `json:"pattern"`
+		Format         string `json:"format"`
+		NationalFormat string `json:"nationalFormat"`
+	} `json:"formats"`
+}
+
+var (
+	// plansByCountryCode maps ISO 3166-1 alpha-2 country codes to their plan.
+	plansByCountryCode = map[string]*numberingPlan{}
+
+	// plansByCountryPrefix maps country calling codes to the plans that use
+	// it. Plans with LeadingDigits come before the one without.
+	plansByCountryPrefix = map[int][]*numberingPlan{}
+)
+
+func init() {
+	var raw []jsonNumberingPlan
+	if err := json.Unmarshal(numberingPlanJSON, &raw); err != nil {
+		panic("lookup: invalid numbering plan metadata: " + err.Error())
+	}
+
+	for _, r := range raw {
+		plan := &numberingPlan{
+			CountryCode:    r.CountryCode,
+			CountryPrefix:  r.CountryPrefix,
+			NationalPrefix: r.NationalPrefix,
+			Pattern:        mustCompileFull(r.Pattern),
+		}
+		if r.LeadingDigits != "" {
+			plan.LeadingDigits = mustCompilePrefix(r.LeadingDigits)
+		}
+		for _, t := range r.Types {
+			plan.Types = append(plan.Types, numberType{
+				Type:    t.Type,
+				Pattern: mustCompileFull(t.Pattern),
+			})
+		}
+		for _, f := range r.Formats {
+			format := numberFormat{
+				Pattern:        mustCompileFull(f.Pattern),
+				Format:         f.Format,
+				NationalFormat: f.NationalFormat,
+			}
+			if f.LeadingDigits != "" {
+				format.LeadingDigits = mustCompilePrefix(f.LeadingDigits)
+			}
+			plan.Formats = append(plan.Formats, format)
+		}
+
+		plansByCountryCode[plan.CountryCode] = plan
+		if plan.LeadingDigits != nil {
+			plansByCountryPrefix[plan.CountryPrefix] = append([]*numberingPlan{plan}, plansByCountryPrefix[plan.CountryPrefix]...)
+		} else {
+			plansByCountryPrefix[plan.CountryPrefix] = append(plansByCountryPrefix[plan.CountryPrefix], plan)
+		}
+	}
+}
+
+// mustCompileFull compiles expr so it only matches the entire input.
+func mustCompileFull(expr string) *regexp.Regexp {
+	return regexp.MustCompile("^(?:" + expr + ")$")
+}
+
+// mustCompilePrefix compiles expr so it matches the start of the input.
+func mustCompilePrefix(expr string) *regexp.Regexp {
+	return regexp.MustCompile("^(?:" + expr + ")")
+}
+
+// planForNumber finds the plan a national number belongs to, given all plans
+// that share a country prefix.
+func planForNumber(plans []*numberingPlan, nationalNumber string) *numberingPlan {
+	for _, plan := range plans {
+		if plan.LeadingDigits != nil && !plan.LeadingDigits.MatchString(nationalNumber) {
+			continue
+		}
+		if plan.Pattern.MatchString(nationalNumber) {
+			return plan
+		}
+	}
+
+	return nil
+}
+
+// numberType returns the type of a valid national number.
+func (p *numberingPlan) numberType(nationalNumber string) string {
+	for _, t := range p.Types {
+		if t.Pattern.MatchString(nationalNumber) {
+			return t.Type
+		}
+	}
+
+	return TypeUnknown
+}
+
+// format returns the international and national formats of a valid national
+// number. Numbers without a matching format are not grouped.
+func (p *numberingPlan) format(nationalNumber string) (international, national string) {
+	for _, f := range p.Formats {
+		if f.LeadingDigits != nil && !f.LeadingDigits.MatchString(nationalNumber) {
+			continue
+		}
+		if !f.Pattern.MatchString(nationalNumber) {
+			continue
+		}
+
+		international = f.Pattern.ReplaceAllString(nationalNumber, f.Format)
+		if f.NationalFormat != "" {
+			national = f.Pattern.ReplaceAllString(nationalNumber, f.NationalFormat)
+		} else {
+			national = p.NationalPrefix + international
+		}
+
+		return international, national
+	}
+
+	return nationalNumber, p.NationalPrefix + nationalNumber
+}
+
+// hasNationalPrefix reports whether number starts with the plan's national
+// prefix, e.g. the 0 in 0612345678.
+func (p *numberingPlan) hasNationalPrefix(number string) bool {
+	return p.NationalPrefix != "" && strings.HasPrefix(number, p.NationalPrefix)
+}
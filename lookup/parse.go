@@ -0,0 +1,158 @@
+package lookup
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Number types as returned in Lookup.Type.
+const (
+	TypeFixedLine             = "fixed line"
+	TypeMobile                = "mobile"
+	TypeFixedLineOrMobile     = "fixed line or mobile"
+	TypeTollFree              = "toll free"
+	TypePremiumRate           = "premium rate"
+	TypeSharedCost            = "shared cost"
+	TypeVoIP                  = "voip"
+	TypePersonalNumber        = "personal number"
+	TypePager                 = "pager"
+	TypeUniversalAccessNumber = "universal access number"
+	TypeUnknown               = "unknown"
+)
+
+var (
+	// ErrInvalidNumber is returned by Parse when the phone number does not
+	// match the numbering plan of its country.
+	ErrInvalidNumber = errors.New("invalid phone number")
+
+	// ErrUnknownNumberingPlan is returned by Parse when no numbering plan is
+	// available for the number's country. Read can be used instead.
+	ErrUnknownNumberingPlan = errors.New("no numbering plan available for phone number")
+)
+
+// maxCountryPrefixLength is the maximum number of digits in a country calling
+// code.
+const maxCountryPrefixLength = 3
+
+// Parse validates and formats a phone number without calling the API. It
+// returns a Lookup with the same CountryCode, CountryPrefix, PhoneNumber, Type
+// and Formats that Read would, but without Href and HLR. Read is only needed
+// when carrier information is required.
+//
+// The phone number can be in international format (with or without leading
+// + or 00) or, when params.CountryCode is set, in national format. Only the
+// countries in the embedded numbering plan metadata are supported: for other
+// countries ErrUnknownNumberingPlan is returned.
+func Parse(phoneNumber string, params *Params) (*Lookup, error) {
+	digits, international, err := normalizeNumber(phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if international || params == nil || params.CountryCode == "" {
+		return parseInternational(digits)
+	}
+
+	plan, ok := plansByCountryCode[strings.ToUpper(params.CountryCode)]
+	if !ok {
+		return nil, ErrUnknownNumberingPlan
+	}
+
+	if plan.hasNationalPrefix(digits) && plan.Pattern.MatchString(digits[len(plan.NationalPrefix):]) {
+		return newParsedLookup(plan, digits[len(plan.NationalPrefix):]), nil
+	}
+	if plan.Pattern.MatchString(digits) {
+		return newParsedLookup(plan, digits), nil
+	}
+
+	// The API accepts international numbers without a leading + even when a
+	// country code is provided, so we do too.
+	return parseInternational(digits)
+}
+
+// normalizeNumber strips common separators from phoneNumber. It reports
+// whether the number was explicitly written in international format.
+func normalizeNumber(phoneNumber string) (string, bool, error) {
+	phoneNumber = strings.TrimSpace(phoneNumber)
+
+	international := false
+	switch {
+	case strings.HasPrefix(phoneNumber, "+"):
+		international = true
+		phoneNumber = phoneNumber[1:]
+	case strings.HasPrefix(phoneNumber, "00"):
+		international = true
+		phoneNumber = phoneNumber[2:]
+	}
+
+	var b strings.Builder
+	for _, r := range phoneNumber {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ', r == '-', r == '.', r == '(', r == ')':
+			// Separators carry no meaning.
+		default:
+			return "", false, ErrInvalidNumber
+		}
+	}
+
+	if b.Len() == 0 {
+		return "", false, ErrInvalidNumber
+	}
+
+	return b.String(), international, nil
+}
+
+// parseInternational parses digits that start with a country calling code.
+func parseInternational(digits string) (*Lookup, error) {
+	knownPrefix := false
+
+	for i := 1; i <= maxCountryPrefixLength && i < len(digits); i++ {
+		countryPrefix, err := strconv.Atoi(digits[:i])
+		if err != nil {
+			return nil, ErrInvalidNumber
+		}
+
+		plans, ok := plansByCountryPrefix[countryPrefix]
+		if !ok {
+			continue
+		}
+		knownPrefix = true
+
+		if plan := planForNumber(plans, digits[i:]); plan != nil {
+			return newParsedLookup(plan, digits[i:]), nil
+		}
+	}
+
+	if !knownPrefix {
+		return nil, ErrUnknownNumberingPlan
+	}
+
+	return nil, ErrInvalidNumber
+}
+
+// newParsedLookup builds the Lookup for a valid national number.
+func newParsedLookup(plan *numberingPlan, nationalNumber string) *Lookup {
+	countryPrefix := strconv.Itoa(plan.CountryPrefix)
+	international, national := plan.format(nationalNumber)
+
+	// The national number has been validated against the plan, so it only
+	// contains digits and always fits an int64.
+	phoneNumber, _ := strconv.ParseInt(countryPrefix+nationalNumber, 10, 64)
+
+	return &Lookup{
+		CountryCode:   plan.CountryCode,
+		CountryPrefix: plan.CountryPrefix,
+		PhoneNumber:   phoneNumber,
+		Type:          plan.numberType(nationalNumber),
+		Formats: Formats{
+			E164:          "+" + countryPrefix + nationalNumber,
+			International: "+" + countryPrefix + " " + international,
+			National:      national,
+			Rfc3966:       fmt.Sprintf("tel:+%s-%s", countryPrefix, strings.Replace(international, " ", "-", -1)),
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package lookup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMatchesRead(t *testing.T) {
+	expected := &Lookup{}
+	err := json.Unmarshal(mbtest.Testdata(t, "lookupObject.json"), expected)
+	assert.NoError(t, err)
+
+	lookup, err := Parse("31624971134", &Params{CountryCode: "NL"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, expected.CountryCode, lookup.CountryCode)
+	assert.Equal(t, expected.CountryPrefix, lookup.CountryPrefix)
+	assert.Equal(t, expected.PhoneNumber, lookup.PhoneNumber)
+	assert.Equal(t, expected.Type, lookup.Type)
+	assert.Equal(t, expected.Formats, lookup.Formats)
+	assert.Nil(t, lookup.HLR)
+}
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		phoneNumber string
+		params      *Params
+		countryCode string
+		numberType  string
+		formats     Formats
+	}{
+		{"06 24971134", &Params{CountryCode: "nl"}, "NL", TypeMobile, Formats{"+31624971134", "+31 6 24971134", "06 24971134", "tel:+31-6-24971134"}},
+		{"+31 20 123 4567", nil, "NL", TypeFixedLine, Formats{"+31201234567", "+31 20 123 4567", "020 123 4567", "tel:+31-20-123-4567"}},
+		{"0032 470 12 34 56", nil, "BE", TypeMobile, Formats{"+32470123456", "+32 470 12 34 56", "0470 12 34 56", "tel:+32-470-12-34-56"}},
+		{"015123456789", &Params{CountryCode: "DE"}, "DE", TypeMobile, Formats{"+4915123456789", "+49 1512 3456789", "01512 3456789", "tel:+49-1512-3456789"}},
+		{"+33612345678", nil, "FR", TypeMobile, Formats{"+33612345678", "+33 6 12 34 56 78", "06 12 34 56 78", "tel:+33-6-12-34-56-78"}},
+		{"+44 7911 123456", nil, "GB", TypeMobile, Formats{"+447911123456", "+44 7911 123456", "07911 123456", "tel:+44-7911-123456"}},
+		{"612345678", &Params{CountryCode: "ES"}, "ES", TypeMobile, Formats{"+34612345678", "+34 612 34 56 78", "612 34 56 78", "tel:+34-612-34-56-78"}},
+		{"06 1234 5678", &Params{CountryCode: "IT"}, "IT", TypeFixedLine, Formats{"+390612345678", "+39 06 1234 5678", "06 1234 5678", "tel:+39-06-1234-5678"}},
+		{"(201) 555-0123", &Params{CountryCode: "US"}, "US", TypeFixedLineOrMobile, Formats{"+12015550123", "+1 201-555-0123", "(201) 555-0123", "tel:+1-201-555-0123"}},
+		{"+1 800 555 0123", nil, "US", TypeTollFree, Formats{"+18005550123", "+1 800-555-0123", "(800) 555-0123", "tel:+1-800-555-0123"}},
+		{"+1 416 555 0123", nil, "CA", TypeFixedLineOrMobile, Formats{"+14165550123", "+1 416-555-0123", "(416) 555-0123", "tel:+1-416-555-0123"}},
+	}
+
+	for _, tc := range tt {
+		lookup, err := Parse(tc.phoneNumber, tc.params)
+		if !assert.NoError(t, err, tc.phoneNumber) {
+			continue
+		}
+
+		assert.Equal(t, tc.countryCode, lookup.CountryCode, tc.phoneNumber)
+		assert.Equal(t, tc.numberType, lookup.Type, tc.phoneNumber)
+		assert.Equal(t, tc.formats, lookup.Formats, tc.phoneNumber)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tt := []struct {
+		phoneNumber string
+		params      *Params
+		expected    error
+	}{
+		{"", nil, ErrInvalidNumber},
+		{"+31 6 2497113a", nil, ErrInvalidNumber},
+		{"+31 6 249", nil, ErrInvalidNumber},
+		{"0624971134", &Params{CountryCode: "XX"}, ErrUnknownNumberingPlan},
+		{"+81 3 1234 5678", nil, ErrUnknownNumberingPlan},
+	}
+
+	for _, tc := range tt {
+		_, err := Parse(tc.phoneNumber, tc.params)
+		assert.Equal(t, tc.expected, err, tc.phoneNumber)
+	}
+}
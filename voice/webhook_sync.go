@@ -0,0 +1,177 @@
+package voice
+
+import (
+	"fmt"
+	"io"
+
+	messagebird "github.com/messagebird/go-rest-api/v9"
+	"github.com/messagebird/go-rest-api/v9/internal/reconcile"
+)
+
+// WebhookExtraPolicy is what PlanWebhooks does with webhooks that exist but
+// are not desired. Voice webhooks can't be disabled, only deleted.
+type WebhookExtraPolicy string
+
+const (
+	// WebhookExtraKeep leaves extra webhooks alone.
+	WebhookExtraKeep WebhookExtraPolicy = "keep"
+
+	// WebhookExtraDelete deletes extra webhooks.
+	WebhookExtraDelete WebhookExtraPolicy = "delete"
+)
+
+// WebhookAction is a change PlanWebhooks plans for a webhook.
+type WebhookAction string
+
+const (
+	WebhookActionCreate WebhookAction = "create"
+	WebhookActionUpdate WebhookAction = "update"
+	WebhookActionDelete WebhookAction = "delete"
+)
+
+// WebhookSyncOptions configure PlanWebhooks.
+type WebhookSyncOptions struct {
+	// Extra defaults to WebhookExtraKeep.
+	Extra WebhookExtraPolicy
+
+	// Manage limits the webhooks that are deleted as extra ones. If it is
+	// nil, all webhooks are managed.
+	Manage func(webhook *Webhook) bool
+}
+
+// WebhookChange is a planned change of a single webhook. Desired is nil for a
+// delete and Existing for a create. Apply sets Webhook and Err.
+type WebhookChange struct {
+	Action   WebhookAction
+	Desired  *Webhook
+	Existing *Webhook
+	Webhook  *Webhook
+	Err      error
+}
+
+func (c *WebhookChange) String() string {
+	if c.Existing == nil {
+		return string(c.Action) + " " + c.Desired.URL
+	}
+
+	return string(c.Action) + " " + c.Existing.URL + " " + c.Existing.ID
+}
+
+// WebhookPlan is returned by PlanWebhooks. It is empty if the webhooks are in
+// sync.
+type WebhookPlan struct {
+	Changes []*WebhookChange
+}
+
+func (p *WebhookPlan) String() string {
+	return reconcile.Describe(len(p.Changes), func(i int) string {
+		return p.Changes[i].String()
+	})
+}
+
+// PlanWebhooks compares the desired webhooks, of which only the URL and Token
+// are used, with the webhooks of the account and plans the changes that are
+// needed to reconcile them. Webhooks are identified by their URL: desired
+// webhooks that don't exist are created, existing ones with another token are
+// updated, and other webhooks are handled according to the Extra option.
+//
+// Call Apply on the plan to make the changes.
+func PlanWebhooks(client messagebird.Client, desired []*Webhook, options *WebhookSyncOptions) (*WebhookPlan, error) {
+	if options == nil {
+		options = &WebhookSyncOptions{}
+	}
+
+	desiredKeys := make([]string, len(desired))
+	declared := make(map[string]bool, len(desired))
+	for i, wh := range desired {
+		if wh.URL == "" {
+			return nil, fmt.Errorf("desired webhook %d has no url", i)
+		}
+		if declared[wh.URL] {
+			return nil, fmt.Errorf("desired webhook %s is declared twice", wh.URL)
+		}
+		declared[wh.URL] = true
+		desiredKeys[i] = wh.URL
+	}
+
+	existing, err := listAllWebhooks(client)
+	if err != nil {
+		return nil, err
+	}
+
+	existingKeys := make([]string, len(existing))
+	for i, wh := range existing {
+		existingKeys[i] = wh.URL
+	}
+
+	inSync := func(d, e int) bool {
+		return desired[d].Token == existing[e].Token
+	}
+	extra := func(e int) reconcile.Action {
+		if options.Extra != WebhookExtraDelete {
+			return ""
+		}
+		if options.Manage != nil && !options.Manage(existing[e]) {
+			return ""
+		}
+		return reconcile.Delete
+	}
+
+	plan := &WebhookPlan{}
+	for _, step := range reconcile.Plan(desiredKeys, existingKeys, inSync, extra) {
+		change := &WebhookChange{Action: WebhookAction(step.Action)}
+		if step.Desired >= 0 {
+			change.Desired = desired[step.Desired]
+		}
+		if step.Existing >= 0 {
+			change.Existing = existing[step.Existing]
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	return plan, nil
+}
+
+// Apply makes the changes of the plan in order and returns the first error.
+func (p *WebhookPlan) Apply(client messagebird.Client) error {
+	return reconcile.Apply(len(p.Changes), func(i int) error {
+		change := p.Changes[i]
+		switch change.Action {
+		case WebhookActionCreate:
+			change.Webhook, change.Err = CreateWebHook(client, change.Desired.URL, change.Desired.Token)
+		case WebhookActionUpdate:
+			wh := *change.Existing
+			wh.Token = change.Desired.Token
+			if change.Err = wh.Update(client); change.Err == nil {
+				change.Webhook = &wh
+			}
+		case WebhookActionDelete:
+			change.Err = change.Existing.Delete(client)
+		default:
+			change.Err = fmt.Errorf("unknown webhook action %s", change.Action)
+		}
+
+		return change.Err
+	})
+}
+
+func listAllWebhooks(client messagebird.Client) ([]*Webhook, error) {
+	var webhooks []*Webhook
+
+	pag := Webhooks(client)
+	for {
+		page, err := pag.NextPage()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		for _, wh := range page.([]Webhook) {
+			wh := wh
+			webhooks = append(webhooks, &wh)
+		}
+
+		if err == io.EOF {
+			return webhooks, nil
+		}
+	}
+}
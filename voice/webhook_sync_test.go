@@ -0,0 +1,100 @@
+package voice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api/v9/internal/mbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// webhookServer serves a single page of webhooks and records the changes made.
+type webhookServer struct {
+	webhooks []Webhook
+	calls    []string
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var response interface{}
+	id := strings.TrimPrefix(r.URL.Path, "/v1/webhooks/")
+	switch r.Method {
+	case http.MethodGet:
+		data := s.webhooks
+		if r.URL.Query().Get("page") != "1" {
+			data = []Webhook{}
+		}
+		response = map[string]interface{}{
+			"data":       data,
+			"pagination": map[string]int{"totalCount": len(s.webhooks), "pageCount": 1, "currentPage": 1, "perPage": 10},
+		}
+	case http.MethodPost:
+		var req jsonWebhook
+		decodeWebhookRequest(r, &req)
+		s.calls = append(s.calls, "create "+req.URL+" "+req.Token)
+		response = map[string][]Webhook{"data": {{ID: "new", URL: req.URL, Token: req.Token}}}
+	case http.MethodPut:
+		var req Webhook
+		decodeWebhookRequest(r, &req)
+		s.calls = append(s.calls, fmt.Sprintf("update %s %s", id, req.Token))
+		response = map[string][]Webhook{"data": {req}}
+	case http.MethodDelete:
+		s.calls = append(s.calls, "delete "+id)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		panic(err)
+	}
+}
+
+func decodeWebhookRequest(r *http.Request, req interface{}) {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		panic(err)
+	}
+}
+
+func TestPlanWebhooks(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	server := &webhookServer{webhooks: []Webhook{
+		{ID: "insync", URL: "https://example.com/a", Token: "token", CreatedAt: now, UpdatedAt: now},
+		{ID: "drifted", URL: "https://example.com/b", Token: "old", CreatedAt: now, UpdatedAt: now},
+		{ID: "extra", URL: "https://example.com/old", CreatedAt: now, UpdatedAt: now},
+	}}
+	transport, teardown := mbtest.HTTPTestTransport(server)
+	defer teardown()
+
+	client := mbtest.Client(t)
+	client.HTTPClient.Transport = transport
+
+	desired := []*Webhook{
+		{URL: "https://example.com/a", Token: "token"},
+		{URL: "https://example.com/b", Token: "new"},
+		{URL: "https://example.com/c"},
+	}
+
+	plan, err := PlanWebhooks(client, desired, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "update https://example.com/b drifted\ncreate https://example.com/c", plan.String())
+
+	plan, err = PlanWebhooks(client, desired, &WebhookSyncOptions{Extra: WebhookExtraDelete})
+	assert.NoError(t, err)
+	assert.Equal(t, "update https://example.com/b drifted\n"+
+		"create https://example.com/c\n"+
+		"delete https://example.com/old extra", plan.String())
+	assert.Empty(t, server.calls)
+
+	assert.NoError(t, plan.Apply(client))
+	assert.Equal(t, []string{
+		"update drifted new",
+		"create https://example.com/c ",
+		"delete extra",
+	}, server.calls)
+	assert.Equal(t, "new", plan.Changes[0].Webhook.Token)
+}